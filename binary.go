@@ -0,0 +1,242 @@
+package glob
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dlclark/regexp2"
+
+	"github.com/pachyderm/ohmyglob/compiler"
+	"github.com/pachyderm/ohmyglob/syntax/ast"
+)
+
+// binaryVersion is the first byte of the binary form of a Glob, and must be changed whenever that form changes incompatibly
+const binaryVersion = 1
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+// The binary form holds the pattern, its Options, its parsed AST and the regular expression it was compiled to,
+// so that UnmarshalBinary can restore the Glob without parsing or compiling the pattern again
+func (g *Glob) MarshalBinary() ([]byte, error) {
+	var e encoder
+	e.buf.WriteByte(binaryVersion)
+	e.string(g.pattern)
+
+	e.uvarint(uint64(len(g.opts.Separators)))
+	for _, sep := range g.opts.Separators {
+		e.varint(int64(sep))
+	}
+	e.bool(g.opts.IgnoreCase)
+	e.varint(int64(g.opts.MatchTimeout))
+
+	if err := e.node(g.tree); err != nil {
+		return nil, err
+	}
+	e.string(g.r.String())
+	return e.buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, restoring a Glob from the form produced by MarshalBinary
+func (g *Glob) UnmarshalBinary(data []byte) error {
+	d := decoder{r: bytes.NewReader(data)}
+	version, err := d.r.ReadByte()
+	if err != nil {
+		return fmt.Errorf("glob: invalid binary form: %v", err)
+	}
+	if version != binaryVersion {
+		return fmt.Errorf("glob: unsupported binary form version %d", version)
+	}
+
+	pattern := d.string()
+	var opts Options
+	if n := d.uvarint(); n > 0 && d.err == nil {
+		if n > uint64(len(data)) {
+			return errors.New("glob: invalid binary form: too many separators")
+		}
+		opts.Separators = make([]rune, n)
+		for i := range opts.Separators {
+			opts.Separators[i] = rune(d.varint())
+		}
+	}
+	opts.IgnoreCase = d.bool()
+	opts.MatchTimeout = time.Duration(d.varint())
+	tree := d.node(nil)
+	regex := d.string()
+	if d.err != nil {
+		return fmt.Errorf("glob: invalid binary form: %v", d.err)
+	}
+
+	r, err := regexp2.Compile(regex, 0)
+	if err != nil {
+		return fmt.Errorf("glob: invalid binary form: %v", err)
+	}
+	r.MatchTimeout = opts.MatchTimeout
+	if r.MatchTimeout <= 0 {
+		r.MatchTimeout = DefaultMatchTimeout
+	}
+
+	names := []string{""}
+	for _, c := range compiler.Captures(tree) {
+		names = append(names, c.Value.(ast.Capture).Name)
+	}
+	*g = Glob{
+		r:         r,
+		pattern:   pattern,
+		names:     names,
+		tree:      tree,
+		opts:      opts,
+		search:    &lazyRegexp{},
+		segments:  &segmentMatcher{},
+		deadlined: newRegexpPool(r),
+		templates: &templateCache{},
+	}
+	return nil
+}
+
+type encoder struct {
+	buf bytes.Buffer
+	tmp [binary.MaxVarintLen64]byte
+}
+
+func (e *encoder) uvarint(v uint64) {
+	e.buf.Write(e.tmp[:binary.PutUvarint(e.tmp[:], v)])
+}
+
+func (e *encoder) varint(v int64) {
+	e.buf.Write(e.tmp[:binary.PutVarint(e.tmp[:], v)])
+}
+
+func (e *encoder) bool(v bool) {
+	if v {
+		e.buf.WriteByte(1)
+	} else {
+		e.buf.WriteByte(0)
+	}
+}
+
+func (e *encoder) string(s string) {
+	e.uvarint(uint64(len(s)))
+	e.buf.WriteString(s)
+}
+
+func (e *encoder) node(n *ast.Node) error {
+	e.uvarint(uint64(n.Kind))
+	switch v := n.Value.(type) {
+	case nil:
+	case ast.List:
+		e.bool(v.Not)
+		e.string(v.Chars)
+	case ast.POSIX:
+		e.bool(v.Not)
+		e.string(v.Class)
+	case ast.Range:
+		e.bool(v.Not)
+		e.varint(int64(v.Lo))
+		e.varint(int64(v.Hi))
+	case ast.Text:
+		e.string(v.Text)
+	case ast.Capture:
+		e.string(v.Quantifier)
+		e.string(v.Name)
+		e.varint(int64(v.Start))
+		e.varint(int64(v.End))
+	case ast.Sequence:
+		e.string(v.Start)
+		e.string(v.End)
+		e.varint(int64(v.Step))
+	default:
+		return fmt.Errorf("glob: cannot encode node value of type %T", v)
+	}
+	e.uvarint(uint64(len(n.Children)))
+	for _, child := range n.Children {
+		if err := e.node(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decoder reads the binary form, remembering the first error so that it only needs to be checked once at the end
+type decoder struct {
+	r   *bytes.Reader
+	err error
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(d.r)
+	d.err = err
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(d.r)
+	d.err = err
+	return v
+}
+
+func (d *decoder) bool() bool {
+	if d.err != nil {
+		return false
+	}
+	b, err := d.r.ReadByte()
+	d.err = err
+	return b != 0
+}
+
+func (d *decoder) string() string {
+	n := d.uvarint()
+	if d.err != nil {
+		return ""
+	}
+	if n > uint64(d.r.Len()) {
+		d.err = io.ErrUnexpectedEOF
+		return ""
+	}
+	b := make([]byte, n)
+	_, d.err = io.ReadFull(d.r, b)
+	return string(b)
+}
+
+func (d *decoder) node(parent *ast.Node) *ast.Node {
+	kind := ast.Kind(d.uvarint())
+	var value interface{}
+	switch kind {
+	case ast.KindList:
+		value = ast.List{Not: d.bool(), Chars: d.string()}
+	case ast.KindPOSIX:
+		value = ast.POSIX{Not: d.bool(), Class: d.string()}
+	case ast.KindRange:
+		value = ast.Range{Not: d.bool(), Lo: rune(d.varint()), Hi: rune(d.varint())}
+	case ast.KindText:
+		value = ast.Text{Text: d.string()}
+	case ast.KindCapture:
+		value = ast.Capture{Quantifier: d.string(), Name: d.string(), Start: int(d.varint()), End: int(d.varint())}
+	case ast.KindSequence:
+		value = ast.Sequence{Start: d.string(), End: d.string(), Step: int(d.varint())}
+	case ast.KindNothing, ast.KindPattern, ast.KindAny, ast.KindSuper, ast.KindSingle, ast.KindAnyOf:
+	default:
+		if d.err == nil {
+			d.err = fmt.Errorf("unknown node kind %d", kind)
+		}
+	}
+	if d.err != nil {
+		return nil
+	}
+
+	n := ast.NewNode(kind, value)
+	n.Parent = parent
+	children := d.uvarint()
+	for i := uint64(0); i < children && d.err == nil; i++ {
+		n.Children = append(n.Children, d.node(n))
+	}
+	return n
+}
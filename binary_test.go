@@ -0,0 +1,62 @@
+package glob
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	for _, test := range []struct {
+		pattern string
+		opts    Options
+		fixture string
+	}{
+		{"", Options{}, ""},
+		{"data/@<year>(*)/[!a-c]?[[:digit:]]{x,y}**!(z).csv", Options{Separators: []rune{'/'}}, "data/2024/de1x/q/w.csv"},
+		{"*.GO", Options{IgnoreCase: true, MatchTimeout: time.Second}, "main.go"},
+		{"shard-{000..127..2}/{a..c}", Options{Separators: []rune{'/'}}, "shard-042/b"},
+	} {
+		t.Run("", func(t *testing.T) {
+			g := MustCompileWithOptions(test.pattern, test.opts)
+			data, err := g.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var u Glob
+			if err := u.UnmarshalBinary(data); err != nil {
+				t.Fatal(err)
+			}
+			if u.Pattern() != g.Pattern() || !reflect.DeepEqual(u.Options(), g.Options()) || u.Regexp() != g.Regexp() {
+				t.Errorf("pattern %q did not round trip: got %q with %+v", test.pattern, u.Pattern(), u.Options())
+			}
+			if u.tree.String() != g.tree.String() || !reflect.DeepEqual(u.CaptureGroups(), g.CaptureGroups()) {
+				t.Errorf("tree of pattern %q did not round trip:\nact:\t%s\nexp:\t%s", test.pattern, u.tree, g.tree)
+			}
+			if !u.Match(test.fixture) || !reflect.DeepEqual(u.Capture(test.fixture), g.Capture(test.fixture)) {
+				t.Errorf("pattern %q should match %q after round trip", test.pattern, test.fixture)
+			}
+
+			for i := 0; i < len(data); i++ {
+				var truncated Glob
+				if err := truncated.UnmarshalBinary(data[:i]); err == nil {
+					t.Errorf("unmarshalling %d of %d bytes should fail", i, len(data))
+				}
+			}
+		})
+	}
+
+	var u Glob
+	if err := u.UnmarshalBinary([]byte{binaryVersion + 1}); err == nil {
+		t.Errorf("unmarshalling an unknown version should fail")
+	}
+}
+
+func BenchmarkUnmarshalBinaryGlob(b *testing.B) {
+	data, _ := MustCompile(pattern_all).MarshalBinary()
+	for i := 0; i < b.N; i++ {
+		var g Glob
+		g.UnmarshalBinary(data)
+	}
+}
@@ -0,0 +1,136 @@
+package glob
+
+import (
+	"fmt"
+
+	"github.com/pachyderm/ohmyglob/syntax/ast"
+	"github.com/pachyderm/ohmyglob/syntax/lexer"
+)
+
+// ExpandBraces expands the `{...}` alternatives in a pattern the way a shell does, returning one pattern for each combination of them,
+// e.g. `src/{a,b}/*.go` expands to `src/a/*.go` and `src/b/*.go`. Nested braces are expanded too, and all other metacharacters
+// (including escapes, such as an escaped comma) are left as they are, so that each result can be passed to Compile.
+// Sequence expressions such as `{1..10}`, `{01..12}`, `{a..f}` or `{0..100..5}` are expanded into each of their values.
+// As in a shell, other braces without a comma in them, e.g. `{a}`, are not expanded.
+// If the pattern expands to more than limit patterns, ErrExpandLimit is returned before they are built; a limit <= 0 means no limit
+func ExpandBraces(pattern string, limit int) ([]string, error) {
+	result := []string{""}
+	for {
+		start, alternatives, end, err := findBraces(pattern, limit)
+		if err != nil {
+			return nil, err
+		}
+		if alternatives == nil {
+			for i := range result {
+				result[i] += pattern
+			}
+			return result, nil
+		}
+
+		// the alternatives may contain braces of their own
+		var expanded []string
+		for _, alt := range alternatives {
+			e, err := ExpandBraces(alt, limit)
+			if err != nil {
+				return nil, err
+			}
+			expanded = append(expanded, e...)
+			if limit > 0 && len(expanded) > limit {
+				return nil, fmt.Errorf("%w: more than %d", ErrExpandLimit, limit)
+			}
+		}
+		if limit > 0 && len(expanded) > limit/len(result) {
+			return nil, fmt.Errorf("%w: more than %d", ErrExpandLimit, limit)
+		}
+
+		product := make([]string, 0, len(result)*len(expanded))
+		for _, prefix := range result {
+			for _, e := range expanded {
+				product = append(product, prefix+pattern[:start]+e)
+			}
+		}
+		result = product
+		pattern = pattern[end:]
+	}
+}
+
+// findBraces finds the first set of braces in the pattern that should be expanded,
+// returning where it starts and ends, and its comma-separated alternatives
+func findBraces(pattern string, limit int) (int, []string, int, error) {
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '[':
+			i = skipClass(pattern, i)
+		case '{':
+			alternatives, end, err := splitBraces(pattern, i)
+			if err != nil {
+				return 0, nil, 0, err
+			}
+			if len(alternatives) > 1 {
+				return i, alternatives, end, nil
+			}
+			if token := lexer.NewLexer(pattern[i:end]).Next(); token.Type == lexer.Sequence && len(token.Raw) == end-i-2 {
+				seq, err := ast.ParseSequence(token.Raw)
+				if err != nil {
+					return 0, nil, 0, fmt.Errorf("glob: %v in %q", err, pattern)
+				}
+				if limit > 0 && seq.Len() > limit {
+					return 0, nil, 0, fmt.Errorf("%w: more than %d", ErrExpandLimit, limit)
+				}
+				return i, seq.Values(), end, nil
+			}
+			// this brace will not be expanded, but there may be braces inside it that will
+		}
+	}
+	return 0, nil, 0, nil
+}
+
+// splitBraces splits the braces starting at pattern[start] at each top level comma, and returns where the braces end
+func splitBraces(pattern string, start int) ([]string, int, error) {
+	var alternatives []string
+	depth := 0
+	last := start + 1
+	for i := start; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '[':
+			i = skipClass(pattern, i)
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return append(alternatives, pattern[last:i]), i + 1, nil
+			}
+		case ',':
+			if depth == 1 {
+				alternatives = append(alternatives, pattern[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return nil, 0, fmt.Errorf("glob: unclosed brace at offset %d in %q", start, pattern)
+}
+
+// skipClass returns the index of the `]` that closes the character class starting at pattern[start],
+// following the same rules as the lexer. If the class is not closed, the rest of the pattern is skipped
+func skipClass(pattern string, start int) int {
+	inPOSIX := false
+	for i := start + 1; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '[':
+			inPOSIX = true
+		case ']':
+			if !inPOSIX {
+				return i
+			}
+			inPOSIX = false
+		}
+	}
+	return len(pattern)
+}
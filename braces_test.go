@@ -0,0 +1,74 @@
+package glob
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandBraces(t *testing.T) {
+	for _, test := range []struct {
+		pattern  string
+		expanded []string
+		err      bool
+	}{
+		{"", []string{""}, false},
+		{"src/*.go", []string{"src/*.go"}, false},
+		{"src/{a,b}/*.go", []string{"src/a/*.go", "src/b/*.go"}, false},
+		{"a{b,c}d{e,f}", []string{"abde", "abdf", "acde", "acdf"}, false},
+		{"{a,b{c,d}}x", []string{"ax", "bcx", "bdx"}, false},
+		{"{,x}y", []string{"y", "xy"}, false},
+		{"{a}", []string{"{a}"}, false},
+		{"{{a,b}}", []string{"{a}", "{b}"}, false},
+		{`{a\,b,c}`, []string{`a\,b`, "c"}, false},
+		{`\{a,b}`, []string{`\{a,b}`}, false},
+		{"[{,]{x,y}", []string{"[{,]x", "[{,]y"}, false},
+		{"{[,],[[:alpha:],]}", []string{"[,]", "[[:alpha:],]"}, false},
+		{"@(a|{b,c})*", []string{"@(a|b)*", "@(a|c)*"}, false},
+		{"a}b", []string{"a}b"}, false},
+		{"a{b,c", nil, true},
+		{"shard-{08..11}/*", []string{"shard-08/*", "shard-09/*", "shard-10/*", "shard-11/*"}, false},
+		{"{c..a}{1..2}", []string{"c1", "c2", "b1", "b2", "a1", "a2"}, false},
+		{"{0..10..5}", []string{"0", "5", "10"}, false},
+		{"{a,{1..2}}", []string{"a", "1", "2"}, false},
+		{"{1..b}", []string{"{1..b}"}, false},
+		{"{0..99999999}", nil, true},
+	} {
+		t.Run("", func(t *testing.T) {
+			expanded, err := ExpandBraces(test.pattern, 0)
+			if !reflect.DeepEqual(expanded, test.expanded) || (err != nil) != test.err {
+				t.Errorf("pattern %q should expand to %q (error: %v), but got %q (error: %v)", test.pattern, test.expanded, test.err, expanded, err)
+			}
+		})
+	}
+}
+
+func TestExpandBracesLimit(t *testing.T) {
+	for _, test := range []struct {
+		pattern string
+		limit   int
+		n       int
+	}{
+		{"{a,b}{c,d}", 4, 4},
+		{"{a,b}{c,d}", 3, -1},
+		{"{a,{b,c}}", 2, -1},
+		{"{1..10}", 9, -1},
+		{"x{1..10}", 10, 10},
+		{strings.Repeat("{a,b}", 32), 1000, -1},
+		{"{1..65536}{1..65536}", 1000000, -1},
+	} {
+		t.Run("", func(t *testing.T) {
+			expanded, err := ExpandBraces(test.pattern, test.limit)
+			if test.n < 0 {
+				if !errors.Is(err, ErrExpandLimit) {
+					t.Errorf("pattern %q should be over the limit of %d, but got %d patterns (error: %v)", test.pattern, test.limit, len(expanded), err)
+				}
+				return
+			}
+			if err != nil || len(expanded) != test.n {
+				t.Errorf("pattern %q should expand to %d patterns, but got %d (error: %v)", test.pattern, test.n, len(expanded), err)
+			}
+		})
+	}
+}
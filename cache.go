@@ -0,0 +1,102 @@
+package glob
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+)
+
+// Cache is a bounded cache of compiled globs, keyed by pattern and Options.
+// Once it is full, the least recently used glob is evicted to make room for a new one.
+// A Cache is safe for concurrent use by multiple goroutines
+type Cache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	lru     *list.List // front is most recently used
+	stats   CacheStats
+}
+
+// CacheStats counts how a Cache has been used
+type CacheStats struct {
+	// Hits is the number of times a glob was found in the cache
+	Hits uint64
+	// Misses is the number of times a glob had to be compiled
+	Misses uint64
+	// Evictions is the number of globs removed to make room for others
+	Evictions uint64
+}
+
+type cacheEntry struct {
+	key string
+	g   *Glob
+}
+
+// NewCache creates a Cache that holds at most size globs
+func NewCache(size int) *Cache {
+	if size < 1 {
+		size = 1
+	}
+	return &Cache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// Compile is the same as the package level Compile, except that the result is taken from the cache if possible
+func (c *Cache) Compile(pattern string, separators ...rune) (*Glob, error) {
+	return c.CompileWithOptions(pattern, Options{Separators: separators})
+}
+
+// CompileWithOptions is the same as the package level CompileWithOptions, except that the result is taken from the cache if possible.
+// Patterns that fail to compile are not cached
+func (c *Cache) CompileWithOptions(pattern string, opts Options) (*Glob, error) {
+	key := fmt.Sprintf("%q %v %d %s", string(opts.Separators), opts.IgnoreCase, opts.MatchTimeout, pattern)
+
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(e)
+		c.stats.Hits++
+		c.mu.Unlock()
+		return e.Value.(*cacheEntry).g, nil
+	}
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	// compile without holding the lock, so that a slow pattern does not hold up other goroutines
+	g, err := CompileWithOptions(pattern, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		// another goroutine compiled the same pattern in the meantime
+		c.lru.MoveToFront(e)
+		return e.Value.(*cacheEntry).g, nil
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, g: g})
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+		c.stats.Evictions++
+	}
+	return g, nil
+}
+
+// Len returns the number of globs in the cache
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Stats returns how the cache has been used so far
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
@@ -0,0 +1,60 @@
+package glob
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	c := NewCache(2)
+
+	a1, err := c.Compile("*.go", '/')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a2, _ := c.Compile("*.go", '/'); a2 != a1 {
+		t.Errorf("compiling the same pattern twice should return the cached glob")
+	}
+	if b, _ := c.Compile("*.go"); b == a1 {
+		t.Errorf("compiling the same pattern with different separators should not return the cached glob")
+	}
+	if _, err := c.Compile("[a-"); err == nil {
+		t.Errorf("compiling an invalid pattern should fail")
+	}
+	if stats := c.Stats(); stats != (CacheStats{Hits: 1, Misses: 3}) {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	// "*.go" without separators is now the least recently used
+	c.Compile("*.go", '/')
+	c.Compile("*.txt")
+	if stats := c.Stats(); stats.Evictions != 1 || c.Len() != 2 {
+		t.Errorf("cache should have evicted one glob, but got %+v with %d globs", stats, c.Len())
+	}
+	if a3, _ := c.Compile("*.go", '/'); a3 != a1 {
+		t.Errorf("recently used glob should not have been evicted")
+	}
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	c := NewCache(8)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				pattern := fmt.Sprintf("*.%d", (i+j)%16)
+				g, err := c.Compile(pattern)
+				if err != nil || g.Pattern() != pattern {
+					t.Errorf("compiling %q returned %v, %v", pattern, g, err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if stats := c.Stats(); stats.Hits+stats.Misses != 800 || c.Len() != 8 {
+		t.Errorf("unexpected stats %+v with %d globs", stats, c.Len())
+	}
+}
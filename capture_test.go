@@ -1,6 +1,10 @@
 package glob
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
 	"testing"
 )
 
@@ -70,6 +74,16 @@ func TestCaptureGlob(t *testing.T) {
 			}
 			results := g.Capture(test.match)
 
+			byteResults := g.CaptureBytes([]byte(test.match))
+			if len(byteResults) != len(results) {
+				t.Errorf("pattern %q matching %q captured %d subgroups as strings, but %d as bytes", test.pattern, test.match, len(results), len(byteResults))
+			}
+			for i := range byteResults {
+				if i < len(results) && string(byteResults[i]) != results[i] {
+					t.Errorf("pattern %q matching %q captured subgroup %q as a string, but %q as bytes", test.pattern, test.match, results[i], byteResults[i])
+				}
+			}
+
 			if len(results) > 0 {
 				test.submatches = append([]string{test.match}, test.submatches...)
 			}
@@ -102,6 +116,8 @@ func TestReplaceGlob(t *testing.T) {
 		replace("name=(*)/year=(*)/(*).dat", "name=adele/year=2016/photos.dat", "$3 of $1 from $2", "photos of adele from 2016"),
 		replace("name=(*)/year=(*)/(*).dat", "name=adele/year=2016/photos.dat", "$0", "name=adele/year=2016/photos.dat"),
 		replace("name=!(adele)/year=(2?1(?))/(*).dat", "name=roxanne/year=2514/puzzle.dat", "$1 from the $3th year of the decade, obtained a $4", "roxanne from the 4th year of the decade, obtained a puzzle"),
+		replace("name=@<name>(*)/year=@<year>(*)/(*).dat", "name=adele/year=2016/photos.dat", "${year}/${name}/$3", "2016/adele/photos"),
+		replace("name=@<name>(*)/year=@<year>(*)/(*).dat", "name=adele/year=2016/photos.dat", "$year ${missing}$01", "$year ${missing}"),
 	} {
 		t.Run("", func(t *testing.T) {
 			g, err := Compile(test.pattern)
@@ -114,6 +130,136 @@ func TestReplaceGlob(t *testing.T) {
 				t.Errorf("replacement template %q matching %q should have returned %+v, but got %+v\n",
 					test.template, test.fixture, test.replacement, replacement)
 			}
+
+			dst := []byte("> ")
+			appended := g.AppendReplace(dst, []byte(test.fixture), test.template)
+			if string(appended) != "> "+test.replacement {
+				t.Errorf("appended replacement template %q matching %q should have returned %+v, but got %+v\n",
+					test.template, test.fixture, "> "+test.replacement, string(appended))
+			}
+		})
+	}
+}
+
+func TestCaptureMap(t *testing.T) {
+	for _, test := range []struct {
+		pattern string
+		fixture string
+		names   []string
+		capture map[string]string
+	}{
+		{"data/(*)/(*).csv", "data/2024/jan.csv", []string{"", "", ""}, map[string]string{}},
+		{"data/@<year>(*)/(*).csv", "data/x.csv", []string{"", "year", ""}, nil},
+		{"data/@<year>(*)/@<month>(*).csv", "data/2024/jan.csv", []string{"", "year", "month"}, map[string]string{"year": "2024", "month": "jan"}},
+		{"+<outer>(?<inner>(a)b)", "abb", []string{"", "outer", "inner"}, map[string]string{"outer": "abb", "inner": ""}},
+		{"*<ext>(.tar|.gz)", ".tar.gz", []string{"", "ext"}, map[string]string{"ext": ".tar.gz"}},
+		// every quantifier has a named form, including both ways of writing a negation
+		{"x-!<rest>(a*)", "x-bc", []string{"", "rest"}, map[string]string{"rest": "bc"}},
+		{"x-^<rest>(a*)", "x-bc", []string{"", "rest"}, map[string]string{"rest": "bc"}},
+		{"x-^<rest>(a*)", "x-ab", []string{"", "rest"}, nil},
+	} {
+		t.Run("", func(t *testing.T) {
+			g, err := Compile(test.pattern, '/')
+			if err != nil {
+				t.Fatal(err)
+			}
+			if names := g.SubexpNames(); !reflect.DeepEqual(names, test.names) {
+				t.Errorf("pattern %q should have group names %q, but got %q", test.pattern, test.names, names)
+			}
+			if capture := g.CaptureMap(test.fixture); !reflect.DeepEqual(capture, test.capture) {
+				t.Errorf("pattern %q matching %q should have captured %v, but got %v", test.pattern, test.fixture, test.capture, capture)
+			}
+		})
+	}
+
+	if _, err := Compile("@<a>(*)/@<a>(*)"); err == nil {
+		t.Errorf("duplicate capture names should fail to compile")
+	}
+}
+
+func TestCaptureIndex(t *testing.T) {
+	for _, test := range []struct {
+		pattern string
+		fixture string
+		indices [][2]int
+	}{
+		{"data/(*)/(*).csv", "data/x.csv", nil},
+		{"data/(*)/(*).csv", "data/2024/jan.csv", [][2]int{{0, 17}, {5, 9}, {10, 13}}},
+		{"data/(*)/(*).csv", "data//.csv", [][2]int{{0, 10}, {5, 5}, {6, 6}}},
+		{"日本/(*)/(*)", "日本/語/ü", [][2]int{{0, 13}, {7, 10}, {11, 13}}},
+		{"a@(y|(x))b", "ayb", [][2]int{{0, 3}, {1, 2}, {-1, -1}}},
+		{"a@(y|(x))b", "axb", [][2]int{{0, 3}, {1, 2}, {1, 2}}},
+		{"a*(b)c", "ac", [][2]int{{0, 2}, {1, 1}}},
+	} {
+		t.Run("", func(t *testing.T) {
+			g, err := Compile(test.pattern, '/')
+			if err != nil {
+				t.Fatal(err)
+			}
+			if indices := g.CaptureIndex(test.fixture); !reflect.DeepEqual(indices, test.indices) {
+				t.Errorf("pattern %q matching %q should have captured %v, but got %v", test.pattern, test.fixture, test.indices, indices)
+			}
+		})
+	}
+}
+
+func TestCaptureAll(t *testing.T) {
+	for _, test := range []struct {
+		pattern  string
+		fixture  string
+		captures [][]string
+	}{
+		{"+(@(*)/)", "a/b/c", nil},
+		{"+(@(*)/)", "a/b/c/", [][]string{{"a/b/c/"}, {"a/b/c/"}, {"a", "b", "c"}}},
+		{"src/*(@(*)/)@(*).go", "src/x.go", [][]string{{"src/x.go"}, {""}, nil, {"x"}}},
+		{"src/*(@(*)/)@(*).go", "src/a/bb/x.go", [][]string{{"src/a/bb/x.go"}, {"a/bb/"}, {"a", "bb"}, {"x"}}},
+		{"+(@(a|b)|c)", "acab", [][]string{{"acab"}, {"acab"}, {"a", "a", "b"}}},
+	} {
+		t.Run("", func(t *testing.T) {
+			g, err := Compile(test.pattern, '/')
+			if err != nil {
+				t.Fatal(err)
+			}
+			if captures := g.CaptureAll(test.fixture); !reflect.DeepEqual(captures, test.captures) {
+				t.Errorf("pattern %q matching %q should have captured %q, but got %q", test.pattern, test.fixture, test.captures, captures)
+			}
 		})
 	}
 }
+
+func TestReplaceFunc(t *testing.T) {
+	g := MustCompile("shard-@<shard>([0-9]*)/@<hour>(*).log", '/')
+	pad := func(captures []string) (string, error) {
+		n, err := strconv.Atoi(captures[1])
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%03d/%v", n, captures[2]), nil
+	}
+	padMap := func(captures map[string]string) (string, error) {
+		return pad([]string{"", captures["shard"], captures["hour"]})
+	}
+
+	for _, test := range []struct {
+		fixture     string
+		replacement string
+		err         bool
+	}{
+		{"shard-7/13.log", "007/13", false},
+		{"shard-12a/13.log", "", true},
+		{"shard-7/13.txt", "", true},
+	} {
+		replacement, err := g.ReplaceFunc(test.fixture, pad)
+		if replacement != test.replacement || (err != nil) != test.err {
+			t.Errorf("replacing %q should have returned %q (error: %v), but got %q, %v", test.fixture, test.replacement, test.err, replacement, err)
+		}
+		replacement, err = g.ReplaceFuncMap(test.fixture, padMap)
+		if replacement != test.replacement || (err != nil) != test.err {
+			t.Errorf("replacing %q by name should have returned %q (error: %v), but got %q, %v", test.fixture, test.replacement, test.err, replacement, err)
+		}
+	}
+
+	if _, err := g.ReplaceFunc("shard-7/13.txt", pad); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("replacing a fixture that does not match should fail with ErrNoMatch, but got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package compiler
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/pachyderm/ohmyglob/syntax/ast"
@@ -50,6 +51,19 @@ func compile(tree *ast.Node, sep []rune) (string, error) {
 		}
 		return "(?:" + anyOfRegex + ")", nil
 
+	// sequences become a non-capturing group of their values OR'd together,
+	// longest first so that searches prefer `10` over `1`
+	case ast.KindSequence:
+		values := tree.Value.(ast.Sequence).Values()
+		sort.SliceStable(values, func(i, j int) bool {
+			return len(values[i]) > len(values[j])
+		})
+		for i, v := range values {
+			values[i] = meta(v)
+		}
+		// a sequence is as aggressive as text, so it bounds the scope of negation
+		return "(?:" + strings.Join(values, "|") + ")" + boundaryDummy, nil
+
 	// subexpresions are simply concatenated
 	case ast.KindPattern:
 		if len(tree.Children) == 0 {
@@ -152,11 +166,42 @@ func compile(tree *ast.Node, sep []rune) (string, error) {
 	return regex, nil
 }
 
+// Options controls how a glob AST is converted into a regular expression
+type Options struct {
+	// Separators are the characters (typically the path directory char: `/` or `\`)
+	// that `*` and `?` should not match
+	Separators []rune
+	// IgnoreCase makes the regular expression match without regard to letter case
+	IgnoreCase bool
+	// Unanchored lets the regular expression match anywhere within the input,
+	// instead of only matching the input as a whole
+	Unanchored bool
+}
+
 // Compile takes a glob AST, and converts it into a regular expression
 // Any separator characters (typically the path directory char: `/` or `\`)
 // are passed in to allow the compiler to handle them correctly
 func Compile(tree *ast.Node, sep []rune) (string, error) {
-	regex, err := compile(tree, sep)
+	return CompileWithOptions(tree, Options{Separators: sep})
+}
+
+// CompileWithOptions is the same as Compile, except that it takes a full set of Options
+func CompileWithOptions(tree *ast.Node, opts Options) (string, error) {
+	// named captures are compiled to ordinary groups, so that they keep their position,
+	// but their names still need to be unique for lookups by name to make sense
+	names := make(map[string]bool)
+	for _, c := range Captures(tree) {
+		name := c.Value.(ast.Capture).Name
+		if name == "" {
+			continue
+		}
+		if names[name] {
+			return "", fmt.Errorf("duplicate capture name %q", name)
+		}
+		names[name] = true
+	}
+
+	regex, err := compile(tree, opts.Separators)
 	if err != nil {
 		return "", err
 	}
@@ -170,6 +215,9 @@ func Compile(tree *ast.Node, sep []rune) (string, error) {
 		// negations should try to match until they reach a boundary
 		if strings.Contains(regex[index:], boundaryDummy) {
 			regex = strings.Replace(regex, closeNegDummy, "", -1)
+		} else if opts.Unanchored && len(opts.Separators) > 0 {
+			// `$` would be the end of the whole text, rather than of the match, so match to the next separator instead
+			regex = strings.Replace(regex, closeNegDummy, "(?!"+dot(opts.Separators)+")", -1)
 		} else {
 			// if no boundaries are imposed, match to the end of the line
 			regex = strings.Replace(regex, closeNegDummy, "$", -1)
@@ -178,6 +226,28 @@ func Compile(tree *ast.Node, sep []rune) (string, error) {
 	// remove all the dummy markers
 	regex = strings.Replace(regex, boundaryDummy, "", -1)
 
+	flags := ""
+	if opts.IgnoreCase {
+		flags = "(?i)"
+	}
+
+	if opts.Unanchored {
+		return flags + regex, nil
+	}
+
 	// globs are expected to match against the whole thing
-	return "\\A" + regex + "\\z", nil
+	return flags + "\\A" + regex + "\\z", nil
+}
+
+// Captures returns all the capture nodes in the tree, in the order that
+// their groups are numbered in the compiled regular expression
+func Captures(tree *ast.Node) []*ast.Node {
+	var captures []*ast.Node
+	if tree.Kind == ast.KindCapture {
+		captures = append(captures, tree)
+	}
+	for _, child := range tree.Children {
+		captures = append(captures, Captures(child)...)
+	}
+	return captures
 }
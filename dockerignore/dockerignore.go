@@ -0,0 +1,220 @@
+package dockerignore
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	glob "github.com/pachyderm/ohmyglob"
+)
+
+// Rule is a single pattern from a .dockerignore file
+type Rule struct {
+	// Pattern is the cleaned pattern, including any leading `!`
+	Pattern string
+	// Exception is true for rules starting with `!`, which re-include the paths they match
+	Exception bool
+
+	glob *glob.Glob
+}
+
+// Decision explains whether a path is excluded from the build context
+type Decision struct {
+	// Excluded is whether the path is excluded
+	Excluded bool
+	// Rule is the index of the rule that decided the outcome, or -1 if no rule matched
+	Rule int
+	// Pattern is the rule that decided the outcome, or "" if no rule matched
+	Pattern string
+}
+
+// Matcher decides which paths in a build context are excluded by the rules of a .dockerignore file.
+// Rules are evaluated in order and the last one that matches a path decides whether it is excluded,
+// so that `!` exceptions can re-include paths excluded by earlier rules, even inside excluded directories
+type Matcher struct {
+	rules []*Rule
+}
+
+// ParseRule parses a pattern from a .dockerignore file into a Rule.
+// As in Docker, the pattern is cleaned with filepath.Clean, and any leading `/` is removed, since all patterns are relative to the root of the context
+func ParseRule(pattern string) (*Rule, error) {
+	r := &Rule{}
+	pattern = strings.TrimSpace(pattern)
+	if strings.HasPrefix(pattern, "!") {
+		r.Exception = true
+		pattern = strings.TrimSpace(pattern[1:])
+	}
+	pattern = cleanPath(pattern)
+	if r.Exception {
+		r.Pattern = "!" + pattern
+	} else {
+		r.Pattern = pattern
+	}
+
+	translated, err := translate(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("dockerignore: invalid rule %q: %v", r.Pattern, err)
+	}
+	if r.glob, err = glob.Compile(translated, '/'); err != nil {
+		return nil, fmt.Errorf("dockerignore: invalid rule %q: %v", r.Pattern, err)
+	}
+	return r, nil
+}
+
+// New creates a Matcher from the patterns of a .dockerignore file
+func New(patterns []string) (*Matcher, error) {
+	m := &Matcher{}
+	for _, pattern := range patterns {
+		r, err := ParseRule(pattern)
+		if err != nil {
+			return nil, err
+		}
+		m.rules = append(m.rules, r)
+	}
+	return m, nil
+}
+
+// Load reads a .dockerignore file and creates a Matcher from it. Blank lines and lines starting with `#` are skipped
+func Load(r io.Reader) (*Matcher, error) {
+	patterns, err := ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return New(patterns)
+}
+
+// ReadAll reads the patterns from a .dockerignore file, skipping blank lines and comments
+func ReadAll(r io.Reader) ([]string, error) {
+	var patterns []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return patterns, nil
+}
+
+// Rules returns the rules of the Matcher, in order
+func (m *Matcher) Rules() []*Rule {
+	return m.rules
+}
+
+// Excluded returns true if the path, which is relative to the root of the context, is excluded
+func (m *Matcher) Excluded(path string) bool {
+	return m.Explain(path).Excluded
+}
+
+// Explain returns whether the path, which is relative to the root of the context, is excluded, and which rule decided it
+func (m *Matcher) Explain(path string) Decision {
+	path = cleanPath(path)
+	d := Decision{Rule: -1}
+	for i, r := range m.rules {
+		if r.Match(path) {
+			d = Decision{Excluded: !r.Exception, Rule: i, Pattern: r.Pattern}
+		}
+	}
+	return d
+}
+
+// Match returns true if the rule matches the path or any of its parent directories, so that a rule matching a directory also matches everything in it
+func (r *Rule) Match(path string) bool {
+	path = cleanPath(path)
+	if r.glob.Match(path) {
+		return true
+	}
+	for i := 0; i < len(path); i++ {
+		if path[i] == '/' && r.glob.Match(path[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func cleanPath(path string) string {
+	path = filepath.ToSlash(filepath.Clean(path))
+	if len(path) > 1 && path[0] == '/' {
+		path = path[1:]
+	}
+	return path
+}
+
+// translate converts a Docker pattern into an ohmyglob pattern using `/` as the separator.
+// A `**/` matches zero or more directories, and any other `**` matches anything, including separators
+func translate(pattern string) (string, error) {
+	var buf strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '\\':
+			if i+1 == len(pattern) {
+				return "", filepath.ErrBadPattern
+			}
+			buf.WriteString(pattern[i : i+2])
+			i++
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					buf.WriteString("{,**/}")
+				} else {
+					buf.WriteString("**")
+				}
+				continue
+			}
+			buf.WriteByte(c)
+		case '?':
+			buf.WriteByte(c)
+		case '[':
+			end, err := translateClass(&buf, pattern, i)
+			if err != nil {
+				return "", err
+			}
+			i = end
+		default:
+			buf.WriteString(glob.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	return buf.String(), nil
+}
+
+// translateClass writes the character class starting at pattern[start], which follows the rules of filepath.Match,
+// and returns the index of the `]` that closes it
+func translateClass(buf *strings.Builder, pattern string, start int) (int, error) {
+	buf.WriteByte('[')
+	i := start + 1
+	if i < len(pattern) && pattern[i] == '^' {
+		buf.WriteByte('^')
+		i++
+	}
+	if i < len(pattern) && pattern[i] == ']' {
+		// filepath.Match does not allow empty classes
+		return 0, filepath.ErrBadPattern
+	}
+	for ; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '\\':
+			if i+1 == len(pattern) {
+				return 0, filepath.ErrBadPattern
+			}
+			buf.WriteString(pattern[i : i+2])
+			i++
+		case '[':
+			// ohmyglob would treat this as the start of a POSIX class
+			buf.WriteString(`\[`)
+		case ']':
+			buf.WriteByte(c)
+			return i, nil
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return 0, filepath.ErrBadPattern
+}
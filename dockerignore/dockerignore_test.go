@@ -0,0 +1,93 @@
+package dockerignore
+
+import (
+	"strings"
+	"testing"
+)
+
+// these cases are taken from the examples in https://docs.docker.com/engine/reference/builder/#dockerignore-file
+func TestExcluded(t *testing.T) {
+	for _, test := range []struct {
+		patterns []string
+		path     string
+		decision Decision
+	}{
+		{nil, "main.go", Decision{Excluded: false, Rule: -1}},
+		{[]string{"*/temp*"}, "somedir/temporary.txt", Decision{Excluded: true, Rule: 0, Pattern: "*/temp*"}},
+		{[]string{"*/temp*"}, "somedir/temp", Decision{Excluded: true, Rule: 0, Pattern: "*/temp*"}},
+		{[]string{"*/temp*"}, "somedir/temp/x", Decision{Excluded: true, Rule: 0, Pattern: "*/temp*"}},
+		{[]string{"*/temp*"}, "temp.txt", Decision{Excluded: false, Rule: -1}},
+		{[]string{"*/temp*"}, "a/b/temp.txt", Decision{Excluded: false, Rule: -1}},
+		{[]string{"*/*/temp*"}, "somedir/subdir/temporary.txt", Decision{Excluded: true, Rule: 0, Pattern: "*/*/temp*"}},
+		{[]string{"temp?"}, "tempa", Decision{Excluded: true, Rule: 0, Pattern: "temp?"}},
+		{[]string{"temp?"}, "temp", Decision{Excluded: false, Rule: -1}},
+		{[]string{"**/*.go"}, "main.go", Decision{Excluded: true, Rule: 0, Pattern: "**/*.go"}},
+		{[]string{"**/*.go"}, "a/b/main.go", Decision{Excluded: true, Rule: 0, Pattern: "**/*.go"}},
+		{[]string{"a/**/b"}, "a/b", Decision{Excluded: true, Rule: 0, Pattern: "a/**/b"}},
+		{[]string{"a/**/b"}, "a/x/y/b/c", Decision{Excluded: true, Rule: 0, Pattern: "a/**/b"}},
+		{[]string{"a/**"}, "a/x/y", Decision{Excluded: true, Rule: 0, Pattern: "a/**"}},
+		{[]string{"*.md", "!README.md"}, "README.md", Decision{Excluded: false, Rule: 1, Pattern: "!README.md"}},
+		{[]string{"*.md", "!README.md"}, "CHANGES.md", Decision{Excluded: true, Rule: 0, Pattern: "*.md"}},
+		{[]string{"*.md", "!README*.md", "README-secret.md"}, "README-secret.md", Decision{Excluded: true, Rule: 2, Pattern: "README-secret.md"}},
+		{[]string{"*.md", "!README*.md", "README-secret.md"}, "README-public.md", Decision{Excluded: false, Rule: 1, Pattern: "!README*.md"}},
+		// patterns and paths are cleaned, and anchored at the root
+		{[]string{"/foo/../bar/"}, "bar/x", Decision{Excluded: true, Rule: 0, Pattern: "bar"}},
+		{[]string{"./bar"}, "/bar", Decision{Excluded: true, Rule: 0, Pattern: "bar"}},
+		{[]string{"bar"}, "x/bar", Decision{Excluded: false, Rule: -1}},
+		{[]string{" ! bar "}, "bar", Decision{Excluded: false, Rule: 0, Pattern: "!bar"}},
+		// a directory match covers everything in it, but exceptions can still re-include paths inside it
+		{[]string{"node_modules"}, "node_modules/x/y.js", Decision{Excluded: true, Rule: 0, Pattern: "node_modules"}},
+		{[]string{"docs", "!docs/keep.md"}, "docs/keep.md", Decision{Excluded: false, Rule: 1, Pattern: "!docs/keep.md"}},
+		{[]string{"docs", "!docs/keep.md"}, "docs/other.md", Decision{Excluded: true, Rule: 0, Pattern: "docs"}},
+		// character classes and escapes, and characters that are only special to ohmyglob
+		{[]string{"[a-c]?.txt"}, "bx.txt", Decision{Excluded: true, Rule: 0, Pattern: "[a-c]?.txt"}},
+		{[]string{"[^a-c].txt"}, "b.txt", Decision{Excluded: false, Rule: -1}},
+		{[]string{`\*.txt`}, "*.txt", Decision{Excluded: true, Rule: 0, Pattern: `\*.txt`}},
+		{[]string{`\*.txt`}, "a.txt", Decision{Excluded: false, Rule: -1}},
+		{[]string{"@(a){b}+(c)"}, "@(a){b}+(c)", Decision{Excluded: true, Rule: 0, Pattern: "@(a){b}+(c)"}},
+	} {
+		t.Run("", func(t *testing.T) {
+			m, err := New(test.patterns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decision := m.Explain(test.path)
+			if decision != test.decision {
+				t.Errorf("patterns %q should decide %q as %+v, but got %+v", test.patterns, test.path, test.decision, decision)
+			}
+			if m.Excluded(test.path) != test.decision.Excluded {
+				t.Errorf("patterns %q should exclude %q: %v", test.patterns, test.path, test.decision.Excluded)
+			}
+		})
+	}
+
+	for _, pattern := range []string{"[a", "[]a]", `a\`} {
+		if _, err := New([]string{pattern}); err == nil {
+			t.Errorf("pattern %q should be invalid", pattern)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	m, err := Load(strings.NewReader(`
+# comment
+*/temp*
+
+  */*/temp*
+temp?
+!tempz
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var patterns []string
+	for _, r := range m.Rules() {
+		patterns = append(patterns, r.Pattern)
+	}
+	if strings.Join(patterns, " ") != "*/temp* */*/temp* temp? !tempz" {
+		t.Errorf("unexpected rules %q", patterns)
+	}
+	if !m.Excluded("tempa") || m.Excluded("tempz") {
+		t.Error("tempa should be excluded, and tempz should not")
+	}
+}
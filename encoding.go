@@ -0,0 +1,75 @@
+package glob
+
+import (
+	"fmt"
+	"strings"
+)
+
+// separatorsPrefix starts the optional separators directive in the text form of a Glob, e.g. `(?sep:/)src/*.go`
+const separatorsPrefix = "(?sep:"
+
+// String returns the text form of the Glob, which is the pattern preceded by a `(?sep:...)` directive if it has any separators.
+// Within the directive, `)` and `\` separators are escaped with a `\`
+func (g Glob) String() string {
+	if len(g.opts.Separators) == 0 && !strings.HasPrefix(g.pattern, separatorsPrefix) {
+		return g.pattern
+	}
+	var b strings.Builder
+	b.WriteString(separatorsPrefix)
+	for _, sep := range g.opts.Separators {
+		if sep == ')' || sep == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(sep)
+	}
+	b.WriteByte(')')
+	b.WriteString(g.pattern)
+	return b.String()
+}
+
+// parseText splits the text form of a Glob into its separators and pattern
+func parseText(text string) ([]rune, string) {
+	if !strings.HasPrefix(text, separatorsPrefix) {
+		return nil, text
+	}
+	var seps []rune
+	escaped := false
+	for i, r := range text[len(separatorsPrefix):] {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+			continue
+		case r == ')':
+			return seps, text[len(separatorsPrefix)+i+1:]
+		}
+		seps = append(seps, r)
+	}
+	// the directive was never closed, so it must be part of the pattern
+	return nil, text
+}
+
+// MarshalText implements encoding.TextMarshaler, using the same text form as String
+func (g Glob) MarshalText() ([]byte, error) {
+	return []byte(g.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, compiling the text form of a Glob as produced by String.
+// Any Options other than the separators that g was already compiled with are kept
+func (g *Glob) UnmarshalText(text []byte) error {
+	seps, pattern := parseText(string(text))
+	opts := g.opts
+	opts.Separators = seps
+	compiled, err := CompileWithOptions(pattern, opts)
+	if err != nil {
+		return fmt.Errorf("glob: invalid pattern %q: %v", pattern, err)
+	}
+	*g = *compiled
+	return nil
+}
+
+// Set implements flag.Value, compiling the text form of a Glob in the same way as UnmarshalText
+func (g *Glob) Set(s string) error {
+	return g.UnmarshalText([]byte(s))
+}
@@ -0,0 +1,89 @@
+package glob
+
+import (
+	"encoding/json"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTextRoundTrip(t *testing.T) {
+	for _, test := range []struct {
+		pattern    string
+		separators []rune
+		text       string
+	}{
+		{"", nil, ""},
+		{"src/*.go", nil, "src/*.go"},
+		{"src/*.go", []rune{'/'}, "(?sep:/)src/*.go"},
+		{"a.b", []rune{'.', ')', '\\'}, `(?sep:.\)\\)a.b`},
+		{"(?sep:x)", nil, "(?sep:)(?sep:x)"},
+		{"(?sep:x", nil, "(?sep:)(?sep:x"},
+	} {
+		t.Run("", func(t *testing.T) {
+			g := MustCompile(test.pattern, test.separators...)
+			text, err := g.MarshalText()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(text) != test.text {
+				t.Errorf("pattern %q with separators %q should marshal to %q, but got %q", test.pattern, test.separators, test.text, text)
+			}
+
+			var u Glob
+			if err := u.UnmarshalText(text); err != nil {
+				t.Fatal(err)
+			}
+			if u.Pattern() != test.pattern || !reflect.DeepEqual(u.Separators(), g.Separators()) {
+				t.Errorf("%q should unmarshal to pattern %q with separators %q, but got %q with %q", text, test.pattern, test.separators, u.Pattern(), u.Separators())
+			}
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var config struct {
+		Include Glob  `json:"include"`
+		Exclude *Glob `json:"exclude"`
+	}
+	if err := json.Unmarshal([]byte(`{"include": "(?sep:/)src/**/*.go", "exclude": "(?sep:/)*_test.go"}`), &config); err != nil {
+		t.Fatal(err)
+	}
+	if !config.Include.Match("src/a/b.go") || config.Exclude.Match("a/b_test.go") || !config.Exclude.Match("b_test.go") {
+		t.Errorf("unmarshalled globs do not match as expected: %v, %v", &config.Include, config.Exclude)
+	}
+
+	out, err := json.Marshal(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := `{"include":"(?sep:/)src/**/*.go","exclude":"(?sep:/)*_test.go"}`; string(out) != exp {
+		t.Errorf("globs should marshal to %s, but got %s", exp, out)
+	}
+	// a Glob held by value must marshal the same way, even when its container is not addressable
+	out, err = json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := `{"include":"(?sep:/)src/**/*.go","exclude":"(?sep:/)*_test.go"}`; string(out) != exp {
+		t.Errorf("globs should marshal to %s by value, but got %s", exp, out)
+	}
+
+	err = json.Unmarshal([]byte(`{"include": "src/[a-z"}`), &config)
+	if err == nil || !strings.Contains(err.Error(), `"src/[a-z"`) {
+		t.Errorf("unmarshalling an invalid pattern should fail with a useful error, but got %v", err)
+	}
+}
+
+func TestFlag(t *testing.T) {
+	var g Glob
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&g, "glob", "files to include")
+	if err := fs.Parse([]string{"-glob", "(?sep:/)*.go"}); err != nil {
+		t.Fatal(err)
+	}
+	if !g.Match("a.go") || g.Match("a/b.go") {
+		t.Errorf("glob from flag does not match as expected: %v", &g)
+	}
+}
@@ -0,0 +1,142 @@
+package glob
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pachyderm/ohmyglob/syntax/ast"
+)
+
+// ErrInfinite is returned by Expand when the pattern can match infinitely many strings
+var ErrInfinite = errors.New("glob: pattern matches infinitely many strings")
+
+// ErrExpandLimit is returned by Expand and ExpandBraces when the pattern matches more strings than the limit allows
+var ErrExpandLimit = errors.New("glob: pattern matches too many strings")
+
+// Expand returns every string that the pattern matches, in the order that they appear in the pattern, without duplicates.
+// This is only possible for patterns without `*`, `**`, `?`, negated character classes, or `*(...)`, `+(...)` and `!(...)`,
+// and otherwise ErrInfinite is returned. If the pattern matches more than limit strings, ErrExpandLimit is returned; a limit <= 0 means no limit.
+// Letter case is expanded as written, even if the Glob was compiled with IgnoreCase
+func (g *Glob) Expand(limit int) ([]string, error) {
+	return expandNode(g.tree, limit)
+}
+
+func expandNode(tree *ast.Node, limit int) ([]string, error) {
+	var result []string
+	switch tree.Kind {
+	// subexpressions are concatenated, so every combination of them is matched
+	case ast.KindPattern:
+		result = []string{""}
+		for _, child := range tree.Children {
+			suffixes, err := expandNode(child, limit)
+			if err != nil {
+				return nil, err
+			}
+			product := make([]string, 0, len(result)*len(suffixes))
+			for _, prefix := range result {
+				for _, suffix := range suffixes {
+					product = append(product, prefix+suffix)
+				}
+			}
+			if result, err = dedupe(product, limit); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+
+	// alternatives match any one of their children
+	case ast.KindAnyOf:
+		return expandChildren(tree, limit, nil)
+
+	case ast.KindCapture:
+		switch tree.Value.(ast.Capture).Quantifier {
+		case "@":
+			return expandChildren(tree, limit, nil)
+		case "?":
+			return expandChildren(tree, limit, []string{""})
+		}
+		return nil, ErrInfinite
+
+	case ast.KindText:
+		result = []string{tree.Value.(ast.Text).Text}
+
+	case ast.KindSequence:
+		seq := tree.Value.(ast.Sequence)
+		if limit > 0 && seq.Len() > limit {
+			return nil, fmt.Errorf("%w: more than %d", ErrExpandLimit, limit)
+		}
+		result = seq.Values()
+
+	case ast.KindNothing:
+		result = []string{""}
+
+	// character classes match each of their characters, with ranges expanded in the same way the compiled regexp treats them
+	case ast.KindList:
+		l := tree.Value.(ast.List)
+		if l.Not {
+			return nil, ErrInfinite
+		}
+		chars := []rune(l.Chars)
+		for i := 0; i < len(chars); i++ {
+			lo, hi := chars[i], chars[i]
+			if i+2 < len(chars) && chars[i+1] == '-' {
+				hi = chars[i+2]
+				i += 2
+			}
+			if limit > 0 && int(hi-lo) >= limit {
+				return nil, fmt.Errorf("%w: more than %d", ErrExpandLimit, limit)
+			}
+			for c := lo; c <= hi; c++ {
+				result = append(result, string(c))
+			}
+		}
+
+	case ast.KindRange:
+		r := tree.Value.(ast.Range)
+		if r.Not {
+			return nil, ErrInfinite
+		}
+		if limit > 0 && int(r.Hi-r.Lo) >= limit {
+			return nil, fmt.Errorf("%w: more than %d", ErrExpandLimit, limit)
+		}
+		for c := r.Lo; c <= r.Hi; c++ {
+			result = append(result, string(c))
+		}
+
+	// wildcards and POSIX classes match too many strings to list
+	default:
+		return nil, ErrInfinite
+	}
+	return dedupe(result, limit)
+}
+
+// expandChildren returns the union of the expansions of each child of tree, after any initial strings
+func expandChildren(tree *ast.Node, limit int, initial []string) ([]string, error) {
+	result := initial
+	for _, child := range tree.Children {
+		alternatives, err := expandNode(child, limit)
+		if err != nil {
+			return nil, err
+		}
+		if result, err = dedupe(append(result, alternatives...), limit); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// dedupe removes duplicate strings without changing their order, and checks that there are no more than limit of them
+func dedupe(strs []string, limit int) ([]string, error) {
+	seen := make(map[string]bool, len(strs))
+	result := strs[:0]
+	for _, s := range strs {
+		if !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+	if limit > 0 && len(result) > limit {
+		return nil, fmt.Errorf("%w: more than %d", ErrExpandLimit, limit)
+	}
+	return result, nil
+}
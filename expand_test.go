@@ -0,0 +1,52 @@
+package glob
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	for _, test := range []struct {
+		pattern  string
+		limit    int
+		expanded []string
+		err      error
+	}{
+		{"", 0, []string{""}, nil},
+		{"abc", 0, []string{"abc"}, nil},
+		{`a\*c`, 0, []string{"a*c"}, nil},
+		{"{a,b}/[xy]/@(1|2).txt", 0, []string{"a/x/1.txt", "a/x/2.txt", "a/y/1.txt", "a/y/2.txt", "b/x/1.txt", "b/x/2.txt", "b/y/1.txt", "b/y/2.txt"}, nil},
+		{"{a,b}/[xy]/@(1|2).txt", 8, []string{"a/x/1.txt", "a/x/2.txt", "a/y/1.txt", "a/y/2.txt", "b/x/1.txt", "b/x/2.txt", "b/y/1.txt", "b/y/2.txt"}, nil},
+		{"{a,b}/[xy]/@(1|2).txt", 7, nil, ErrExpandLimit},
+		{"file[0-3]", 0, []string{"file0", "file1", "file2", "file3"}, nil},
+		{"[-a-c]", 0, []string{"-", "a", "b", "c"}, nil},
+		{"x?(y|z)", 0, []string{"x", "xy", "xz"}, nil},
+		{"{a,ab}{bc,c}", 0, []string{"abc", "ac", "abbc"}, nil},
+		{"{a,{b,a}}", 0, []string{"a", "b"}, nil},
+		{"[a-z][a-z][a-z][a-z]", 1000, nil, ErrExpandLimit},
+		{"hour-{00..23..6}", 0, []string{"hour-00", "hour-06", "hour-12", "hour-18"}, nil},
+		{"{1..3}{a..b}", 0, []string{"1a", "1b", "2a", "2b", "3a", "3b"}, nil},
+		{"{1..100}", 10, nil, ErrExpandLimit},
+		{"*.go", 0, nil, ErrInfinite},
+		{"{a,**}", 0, nil, ErrInfinite},
+		{"a?", 0, nil, ErrInfinite},
+		{"[!a]", 0, nil, ErrInfinite},
+		{"+(a)", 0, nil, ErrInfinite},
+		{"*(a)", 0, nil, ErrInfinite},
+		{"!(a)", 0, nil, ErrInfinite},
+	} {
+		t.Run("", func(t *testing.T) {
+			g := MustCompile(test.pattern, '/')
+			expanded, err := g.Expand(test.limit)
+			if !reflect.DeepEqual(expanded, test.expanded) || !errors.Is(err, test.err) {
+				t.Errorf("pattern %q should expand to %q (error %v), but got %q (error %v)", test.pattern, test.expanded, test.err, expanded, err)
+			}
+			for _, s := range expanded {
+				if !g.Match(s) {
+					t.Errorf("pattern %q should match its expansion %q", test.pattern, s)
+				}
+			}
+		})
+	}
+}
@@ -279,6 +279,8 @@ func TestNegationGlob(t *testing.T) {
 		// i've included a fix for https://github.com/micromatch/extglob/issues/10
 		glob(false, "!(*.js|*.json)", "a.js"),
 		glob(true, "!(*.js|*.json)", "a.js.gz"),
+		glob(false, "^(*.js|*.json)", "a.js"),
+		glob(true, "^(*.js|*.json)", "a.js.gz"),
 		glob(true, "!(*.js|*.json)", "a.json.gz"),
 		glob(true, "!(*.js|*.json)", "a.gz"),
 		glob(false, "!(*.js|*.json)", "a.js"),
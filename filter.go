@@ -0,0 +1,89 @@
+package glob
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Filter decides whether paths are included or excluded by an ordered list of rules.
+// Each rule is a pattern, optionally prefixed by `+` to include the paths it matches, or by `-` or `!` to exclude them;
+// rules without a prefix include the paths they match. The last rule that matches a path decides its outcome.
+// If no rule matches, the path is excluded if there are any include rules, and included otherwise,
+// so that a list of only exclusions filters out what it matches, and a list with inclusions acts as an allow list.
+//
+// Since `+(...)` and `!(...)` are also extended globs, a `+` or `!` followed by `(` is part of the pattern rather than a prefix,
+// so that e.g. `!(*.go)` includes everything except Go files, and `-!(*.go)` excludes everything except Go files
+type Filter struct {
+	rules      []string
+	include    []bool
+	hasInclude bool
+	set        *GlobSet
+}
+
+// FilterDecision explains the outcome of a Filter for a path
+type FilterDecision struct {
+	// Included is whether the path is included by the Filter
+	Included bool
+	// Rule is the index of the rule that decided the outcome, or -1 if no rule matched
+	Rule int
+	// Pattern is the rule that decided the outcome, as it was given to CompileFilter, or "" if no rule matched
+	Pattern string
+}
+
+// CompileFilter compiles an ordered list of rules into a Filter, with the given separators used for every pattern as in Compile
+func CompileFilter(rules []string, separators ...rune) (*Filter, error) {
+	f := &Filter{
+		rules:   rules,
+		include: make([]bool, len(rules)),
+	}
+	globs := make([]*Glob, len(rules))
+	for i, rule := range rules {
+		pattern, include := parseRule(rule)
+		g, err := Compile(pattern, separators...)
+		if err != nil {
+			return nil, fmt.Errorf("glob: invalid rule %d %q: %v", i, rule, err)
+		}
+		globs[i] = g
+		f.include[i] = include
+		f.hasInclude = f.hasInclude || include
+	}
+	f.set = NewGlobSet(globs...)
+	return f, nil
+}
+
+// MustCompileFilter is the same as CompileFilter, except that if CompileFilter returns error, this will panic
+func MustCompileFilter(rules []string, separators ...rune) *Filter {
+	f, err := CompileFilter(rules, separators...)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// parseRule splits a rule into its pattern, and whether the pattern includes or excludes what it matches
+func parseRule(rule string) (string, bool) {
+	switch {
+	case strings.HasPrefix(rule, "-"):
+		return rule[1:], false
+	case strings.HasPrefix(rule, "!") && !strings.HasPrefix(rule, "!("):
+		return rule[1:], false
+	case strings.HasPrefix(rule, "+") && !strings.HasPrefix(rule, "+("):
+		return rule[1:], true
+	}
+	return rule, true
+}
+
+// Match returns true if the Filter includes the path
+func (f *Filter) Match(path string) bool {
+	return f.Explain(path).Included
+}
+
+// Explain returns whether the Filter includes the path, and which rule decided it
+func (f *Filter) Explain(path string) FilterDecision {
+	matches := f.set.Matches(path)
+	if len(matches) == 0 {
+		return FilterDecision{Included: !f.hasInclude, Rule: -1}
+	}
+	last := matches[len(matches)-1]
+	return FilterDecision{Included: f.include[last], Rule: last, Pattern: f.rules[last]}
+}
@@ -0,0 +1,45 @@
+package glob
+
+import (
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	for _, test := range []struct {
+		rules    []string
+		path     string
+		decision FilterDecision
+	}{
+		{nil, "a.go", FilterDecision{Included: true, Rule: -1}},
+		{[]string{"-*.go"}, "a.go", FilterDecision{Included: false, Rule: 0, Pattern: "-*.go"}},
+		{[]string{"-*.go"}, "a.py", FilterDecision{Included: true, Rule: -1}},
+		{[]string{"*.go"}, "a.py", FilterDecision{Included: false, Rule: -1}},
+		{[]string{"+**.go", "!vendor/**", "+vendor/keep/**"}, "src/a.go", FilterDecision{Included: true, Rule: 0, Pattern: "+**.go"}},
+		{[]string{"+**.go", "!vendor/**", "+vendor/keep/**"}, "vendor/x/a.go", FilterDecision{Included: false, Rule: 1, Pattern: "!vendor/**"}},
+		{[]string{"+**.go", "!vendor/**", "+vendor/keep/**"}, "vendor/keep/a.go", FilterDecision{Included: true, Rule: 2, Pattern: "+vendor/keep/**"}},
+		{[]string{"+**.go", "!vendor/**", "+vendor/keep/**"}, "README", FilterDecision{Included: false, Rule: -1}},
+		{[]string{"*.go", "-*.go"}, "a.go", FilterDecision{Included: false, Rule: 1, Pattern: "-*.go"}},
+		{[]string{"-*.go", "*.go"}, "a.go", FilterDecision{Included: true, Rule: 1, Pattern: "*.go"}},
+		{[]string{"*", "-*/*"}, "a/b", FilterDecision{Included: false, Rule: 1, Pattern: "-*/*"}},
+		{[]string{"*", "-*/*"}, "a", FilterDecision{Included: true, Rule: 0, Pattern: "*"}},
+		{[]string{"!(*.go)"}, "a.py", FilterDecision{Included: true, Rule: 0, Pattern: "!(*.go)"}},
+		{[]string{"-!(*.go)"}, "a.py", FilterDecision{Included: false, Rule: 0, Pattern: "-!(*.go)"}},
+		{[]string{"+(a|b).txt"}, "ab.txt", FilterDecision{Included: true, Rule: 0, Pattern: "+(a|b).txt"}},
+		{[]string{"++.txt"}, "+.txt", FilterDecision{Included: true, Rule: 0, Pattern: "++.txt"}},
+	} {
+		t.Run("", func(t *testing.T) {
+			f := MustCompileFilter(test.rules, '/')
+			decision := f.Explain(test.path)
+			if decision != test.decision {
+				t.Errorf("rules %q should decide %q as %+v, but got %+v", test.rules, test.path, test.decision, decision)
+			}
+			if f.Match(test.path) != test.decision.Included {
+				t.Errorf("rules %q should include %q: %v", test.rules, test.path, test.decision.Included)
+			}
+		})
+	}
+
+	if _, err := CompileFilter([]string{"*.go", "-[a"}); err == nil {
+		t.Error("compiling a filter with an invalid rule should fail")
+	}
+}
@@ -0,0 +1,87 @@
+package glob
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"strings"
+)
+
+// Match is a path in a filesystem that matched a pattern
+type Match struct {
+	// Path is the path of the file or directory in the filesystem
+	Path string
+	// Captures are the subexpressions captured from Path, starting from the first capture group,
+	// i.e. the same as Capture()[1:], so that they can be passed to Render
+	Captures []string
+}
+
+// GlobFS returns every file and directory in fsys that matches the pattern, in lexical order.
+// Only the directories that could contain a match are read.
+// The pattern is compiled with `/` as the separator, and is matched against paths as used by fs.FS,
+// which are relative to the root of fsys and never start with `/`
+func GlobFS(fsys fs.FS, pattern string) ([]Match, error) {
+	var matches []Match
+	err := WalkGlob(fsys, pattern, func(m Match) error {
+		matches = append(matches, m)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// WalkGlob calls fn for every file and directory in fsys that matches the pattern, in lexical order, as GlobFS does.
+// If fn returns an error, walking stops and that error is returned
+func WalkGlob(fsys fs.FS, pattern string, fn func(Match) error) error {
+	g, err := Compile(pattern, '/')
+	if err != nil {
+		return err
+	}
+	return g.walkFS(fsys, fn)
+}
+
+func (g *Glob) walkFS(fsys fs.FS, fn func(Match) error) error {
+	// every match must be in the base directory, so that is where walking starts
+	base := strings.TrimSuffix(g.Base(), "/")
+	if base == "" {
+		base = "."
+	}
+	if !fs.ValidPath(base) {
+		// paths in a fs.FS never start with `/`, or contain `.` or `..` elements
+		return nil
+	}
+	err := fs.WalkDir(fsys, base, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == "." {
+			return nil
+		}
+		captures, err := g.CaptureContext(context.Background(), path)
+		if err != nil {
+			return err
+		}
+		if captures != nil {
+			if err := fn(Match{Path: path, Captures: captures[1:]}); err != nil {
+				return err
+			}
+		}
+		if d.IsDir() && !g.CouldMatchUnder(path) {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) && isRootError(err, base) {
+		// nothing can match if the directory that every match must be in does not exist
+		return nil
+	}
+	return err
+}
+
+// isRootError reports whether err came from reading the root of a walk
+func isRootError(err error, root string) bool {
+	var pathErr *fs.PathError
+	return errors.As(err, &pathErr) && pathErr.Path == root
+}
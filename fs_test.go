@@ -0,0 +1,130 @@
+package glob
+
+import (
+	"errors"
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+var testFS = fstest.MapFS{
+	"README.md":               {},
+	"data/2023/jan.csv":       {},
+	"data/2024/feb.csv":       {},
+	"data/2024/mar.csv":       {},
+	"data/2024/notes.txt":     {},
+	"data/2024/q1/apr.csv":    {},
+	"src/main.go":             {},
+	"src/lib/lib.go":          {},
+	"src/lib/lib_test.go":     {},
+	"src/vendor/x/x.go":       {},
+	"weird/@(a)/{b}.txt":      {},
+	"empty/.keep":             {},
+	"data/2024/q1/archive/.x": {},
+}
+
+func TestGlobFS(t *testing.T) {
+	for _, test := range []struct {
+		pattern string
+		matches []Match
+	}{
+		{"data/@(*)/@(*).csv", []Match{
+			{"data/2023/jan.csv", []string{"2023", "jan"}},
+			{"data/2024/feb.csv", []string{"2024", "feb"}},
+			{"data/2024/mar.csv", []string{"2024", "mar"}},
+		}},
+		{"data/2024/**.csv", []Match{
+			{"data/2024/feb.csv", []string{}},
+			{"data/2024/mar.csv", []string{}},
+			{"data/2024/q1/apr.csv", []string{}},
+		}},
+		{"src/**/*_test.go", []Match{
+			{"src/lib/lib_test.go", []string{}},
+		}},
+		{"src/*", []Match{
+			{"src/lib", []string{}},
+			{"src/main.go", []string{}},
+			{"src/vendor", []string{}},
+		}},
+		{"*.md", []Match{
+			{"README.md", []string{}},
+		}},
+		{"src/!(vendor)/*.go", []Match{
+			{"src/lib/lib.go", []string{"lib"}},
+			{"src/lib/lib_test.go", []string{"lib"}},
+		}},
+		{`weird/\@\(a\)/\{b\}.txt`, []Match{
+			{"weird/@(a)/{b}.txt", []string{}},
+		}},
+		{"missing/**", nil},
+		{"/src/*", nil},
+		{"../src/*", nil},
+	} {
+		t.Run("", func(t *testing.T) {
+			matches, err := GlobFS(testFS, test.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(matches, test.matches) {
+				t.Errorf("pattern %q should match %v, but got %v", test.pattern, test.matches, matches)
+			}
+		})
+	}
+
+	if _, err := GlobFS(testFS, "[a"); err == nil {
+		t.Error("an invalid pattern should fail")
+	}
+}
+
+func TestWalkGlob(t *testing.T) {
+	stop := errors.New("stop")
+	var paths []string
+	err := WalkGlob(testFS, "data/**.csv", func(m Match) error {
+		paths = append(paths, m.Path)
+		if len(paths) == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("WalkGlob should return the error from its callback, but got %v", err)
+	}
+	if !reflect.DeepEqual(paths, []string{"data/2023/jan.csv", "data/2024/feb.csv"}) {
+		t.Errorf("unexpected paths %q", paths)
+	}
+}
+
+// readDirFS records every directory that is read from it
+type readDirFS struct {
+	fstest.MapFS
+	read []string
+}
+
+func (f *readDirFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	f.read = append(f.read, name)
+	return f.MapFS.ReadDir(name)
+}
+
+func TestGlobFSPruning(t *testing.T) {
+	for _, test := range []struct {
+		pattern string
+		read    []string
+	}{
+		{"data/@(*)/@(*).csv", []string{"data", "data/2023", "data/2024"}},
+		{"data/*/q1/*.csv", []string{"data", "data/2023", "data/2024", "data/2024/q1"}},
+		{"src/!(vendor)/*.go", []string{"src", "src/lib"}},
+		{"*/lib/*", []string{".", "data", "empty", "src", "src/lib", "weird"}},
+		{"src/**", []string{"src", "src/lib", "src/vendor", "src/vendor/x"}},
+	} {
+		t.Run("", func(t *testing.T) {
+			fsys := &readDirFS{MapFS: testFS}
+			if _, err := GlobFS(fsys, test.pattern); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(fsys.read, test.read) {
+				t.Errorf("pattern %q should read %q, but read %q", test.pattern, test.read, fsys.read)
+			}
+		})
+	}
+}
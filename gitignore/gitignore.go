@@ -0,0 +1,326 @@
+package gitignore
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	glob "github.com/pachyderm/ohmyglob"
+)
+
+// Rule is a single pattern from an ignore file
+type Rule struct {
+	// Pattern is the line of the ignore file that the rule was parsed from
+	Pattern string
+	// Negate is true for rules starting with `!`, which re-include the paths they match
+	Negate bool
+	// DirOnly is true for rules ending with `/`, which only match directories
+	DirOnly bool
+
+	// glob is nil if the pattern is not valid, in which case the rule never matches
+	glob *glob.Glob
+}
+
+// ParseRule parses a line of an ignore file into a Rule.
+// Blank lines and comments do not contain a rule, and neither do lines with an empty pattern such as `/` or `!/`,
+// so for them ParseRule returns nil. As in git, a line is never rejected: a rule with an invalid pattern
+// (e.g. `[z-a]`) never matches anything
+func ParseRule(line string) *Rule {
+	line = strings.TrimSuffix(line, "\r")
+	if strings.HasPrefix(line, "#") {
+		return nil
+	}
+	pattern := trimTrailingSpaces(line)
+	if pattern == "" {
+		return nil
+	}
+
+	r := &Rule{Pattern: line}
+	if strings.HasPrefix(pattern, "!") {
+		r.Negate = true
+		pattern = pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "/") {
+		r.DirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	if pattern == "" {
+		return nil
+	}
+
+	if g, err := glob.Compile(translate(pattern), '/'); err == nil {
+		r.glob = g
+	}
+	return r
+}
+
+// Parse parses all the rules in an ignore file. It only fails if the ignore file cannot be read
+func Parse(r io.Reader) ([]*Rule, error) {
+	var rules []*Rule
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if rule := ParseRule(scanner.Text()); rule != nil {
+			rules = append(rules, rule)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// trimTrailingSpaces removes the trailing spaces from a line, unless they are escaped with a backslash
+func trimTrailingSpaces(line string) string {
+	end := len(line)
+	for end > 0 && line[end-1] == ' ' {
+		// count the backslashes before the space, since an escaped backslash does not escape the space
+		escapes := 0
+		for i := end - 2; i >= 0 && line[i] == '\\'; i-- {
+			escapes++
+		}
+		if escapes%2 == 1 {
+			break
+		}
+		end--
+	}
+	return line[:end]
+}
+
+// translate converts a gitignore pattern (without any `!` or trailing `/`) into an ohmyglob pattern using `/` as the separator.
+// A pattern with a `/` at the start or in the middle is relative to the directory of the ignore file,
+// and any other pattern can match at any depth below it. `**` is only special as a whole path segment:
+// a leading `**/` or a middle `/**/` matches zero or more directories, and a trailing `/**` matches everything inside
+func translate(pattern string) string {
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var buf strings.Builder
+	if !anchored {
+		buf.WriteString("{,**/}")
+	}
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		if segment == "**" {
+			if last {
+				buf.WriteString("**")
+			} else {
+				buf.WriteString("{,**/}")
+			}
+			continue
+		}
+		translateSegment(&buf, segment)
+		if !last {
+			buf.WriteString("/")
+		}
+	}
+	return buf.String()
+}
+
+// translateSegment writes a single path segment of a gitignore pattern, quoting any ohmyglob metacharacters that gitignore does not have,
+// and treating any other run of asterisks as a single `*`
+func translateSegment(buf *strings.Builder, segment string) {
+	for i := 0; i < len(segment); i++ {
+		switch c := segment[i]; c {
+		case '\\':
+			if i+1 == len(segment) {
+				// a trailing backslash escapes nothing, so it is just a backslash
+				buf.WriteString(`\\`)
+				continue
+			}
+			buf.WriteString(segment[i : i+2])
+			i++
+		case '*':
+			buf.WriteByte(c)
+			for i+1 < len(segment) && segment[i+1] == '*' {
+				i++
+			}
+		case '?':
+			buf.WriteByte(c)
+		case '[':
+			end := classEnd(segment, i)
+			if end < 0 {
+				// an unclosed bracket is just a bracket
+				buf.WriteString(glob.QuoteMeta(segment[i : i+1]))
+				continue
+			}
+			translateClass(buf, segment[i+1:end])
+			i = end
+		default:
+			buf.WriteString(glob.QuoteMeta(segment[i : i+1]))
+		}
+	}
+}
+
+// classEnd returns the index of the `]` that closes the bracket expression starting at segment[start], or -1 if it is not closed.
+// As in fnmatch, a `]` straight after the `[` (or after a `!` or `^` that negates the class) is part of the class
+func classEnd(segment string, start int) int {
+	i := start + 1
+	if i < len(segment) && (segment[i] == '!' || segment[i] == '^') {
+		i++
+	}
+	if i < len(segment) && segment[i] == ']' {
+		i++
+	}
+	for ; i < len(segment); i++ {
+		switch segment[i] {
+		case '\\':
+			i++
+		case '[':
+			if end := posixEnd(segment, i); end > 0 {
+				i = end
+			}
+		case ']':
+			return i
+		}
+	}
+	return -1
+}
+
+// posixEnd returns the index of the `]` that closes the POSIX class (e.g. `[:alpha:]`) starting at s[start], or -1 if there is not one
+func posixEnd(s string, start int) int {
+	if !strings.HasPrefix(s[start:], "[:") {
+		return -1
+	}
+	if end := strings.Index(s[start+2:], ":]"); end >= 0 {
+		return start + 2 + end + 1
+	}
+	return -1
+}
+
+// translateClass writes the contents of a bracket expression as an ohmyglob character class,
+// which needs any `[` that does not start a POSIX class and a leading `]` to be escaped
+func translateClass(buf *strings.Builder, class string) {
+	buf.WriteByte('[')
+	if strings.HasPrefix(class, "!") || strings.HasPrefix(class, "^") {
+		buf.WriteByte(class[0])
+		class = class[1:]
+	}
+	if strings.HasPrefix(class, "]") {
+		buf.WriteString(`\]`)
+		class = class[1:]
+	}
+	for i := 0; i < len(class); i++ {
+		switch class[i] {
+		case '\\':
+			if i+1 == len(class) {
+				buf.WriteString(`\\`)
+				continue
+			}
+			// the escape means the same to ohmyglob, so it is kept as it is
+			buf.WriteString(class[i : i+2])
+			i++
+		case '[':
+			if end := posixEnd(class, i); end > 0 {
+				buf.WriteString(class[i : end+1])
+				i = end
+				continue
+			}
+			buf.WriteString(`\[`)
+		default:
+			buf.WriteByte(class[i])
+		}
+	}
+	buf.WriteByte(']')
+}
+
+// Match returns true if the rule matches the path, which must be relative to the directory of the ignore file the rule is from.
+// A rule that matches a path ignores it, unless the rule is negated
+func (r *Rule) Match(path string, isDir bool) bool {
+	if r.glob == nil || (r.DirOnly && !isDir) {
+		return false
+	}
+	return r.glob.Match(path)
+}
+
+// Matcher decides which paths are ignored by a hierarchy of ignore files.
+// Rules in an ignore file apply to the paths below its directory, and take precedence over rules from the ignore files in parent directories
+type Matcher struct {
+	dirs []ignoreFile
+}
+
+type ignoreFile struct {
+	dir   string
+	rules []*Rule
+}
+
+// New creates a Matcher with no rules, which ignores nothing
+func New() *Matcher {
+	return &Matcher{}
+}
+
+// Add parses the ignore file in dir, which is relative to the root of the tree the Matcher is used on ("" or "." for the root).
+// It only fails if the ignore file cannot be read
+func (m *Matcher) Add(dir string, r io.Reader) error {
+	rules, err := Parse(r)
+	if err != nil {
+		return fmt.Errorf("%w in %s", err, path.Join(dir, ".gitignore"))
+	}
+	m.AddRules(dir, rules...)
+	return nil
+}
+
+// AddRules adds rules to the ignore file for dir, after any that have already been added
+func (m *Matcher) AddRules(dir string, rules ...*Rule) {
+	dir = cleanPath(dir)
+	for i := range m.dirs {
+		if m.dirs[i].dir == dir {
+			m.dirs[i].rules = append(m.dirs[i].rules, rules...)
+			return
+		}
+	}
+	m.dirs = append(m.dirs, ignoreFile{dir: dir, rules: rules})
+	// parent directories must be checked before their children
+	sort.SliceStable(m.dirs, func(i, j int) bool {
+		return depth(m.dirs[i].dir) < depth(m.dirs[j].dir)
+	})
+}
+
+// Ignored returns true if the path, which is relative to the root of the tree, is ignored.
+// As in git, a path inside an ignored directory is always ignored, even if a rule would re-include it
+func (m *Matcher) Ignored(name string, isDir bool) bool {
+	name = cleanPath(name)
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if name[i] == '/' && m.match(name[:i], true) {
+			return true
+		}
+	}
+	return m.match(name, isDir)
+}
+
+// match applies every rule that covers the path, without considering its parent directories
+func (m *Matcher) match(name string, isDir bool) bool {
+	ignored := false
+	for _, f := range m.dirs {
+		rel := name
+		if f.dir != "" {
+			if !strings.HasPrefix(name, f.dir+"/") {
+				continue
+			}
+			rel = name[len(f.dir)+1:]
+		}
+		for _, rule := range f.rules {
+			if rule.Match(rel, isDir) {
+				ignored = !rule.Negate
+			}
+		}
+	}
+	return ignored
+}
+
+func cleanPath(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+func depth(dir string) int {
+	if dir == "" {
+		return 0
+	}
+	return strings.Count(dir, "/") + 1
+}
@@ -0,0 +1,176 @@
+package gitignore
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// these cases are taken from the examples in https://git-scm.com/docs/gitignore
+func TestRuleMatch(t *testing.T) {
+	for _, test := range []struct {
+		rule  string
+		path  string
+		isDir bool
+		match bool
+	}{
+		// a pattern without a slash matches at any level
+		{"hello.*", "hello.txt", false, true},
+		{"hello.*", "a/hello.c", false, true},
+		{"hello.*", "hello", false, false},
+		{"frotz", "frotz", false, true},
+		{"frotz", "a/frotz", true, true},
+		// a leading slash anchors the pattern
+		{"/hello.*", "hello.txt", false, true},
+		{"/hello.*", "a/hello.c", false, false},
+		// a slash in the middle anchors the pattern too
+		{"doc/frotz/", "doc/frotz", true, true},
+		{"doc/frotz/", "a/doc/frotz", true, false},
+		{"doc/frotz", "doc/frotz", false, true},
+		// a trailing slash only matches directories
+		{"frotz/", "frotz", true, true},
+		{"frotz/", "a/frotz", true, true},
+		{"frotz/", "frotz", false, false},
+		// `*` does not match a slash
+		{"foo/*", "foo/test.json", false, true},
+		{"foo/*", "foo/bar", true, true},
+		{"foo/*", "foo/bar/hello.c", false, false},
+		// a leading `**/` matches in all directories
+		{"**/foo", "foo", false, true},
+		{"**/foo", "a/b/foo", true, true},
+		{"**/foo/bar", "foo/bar", false, true},
+		{"**/foo/bar", "a/foo/bar", false, true},
+		{"**/foo/bar", "a/foo/baz", false, false},
+		// a trailing `/**` matches everything inside
+		{"abc/**", "abc/x", false, true},
+		{"abc/**", "abc/x/y", false, true},
+		{"abc/**", "abc", true, false},
+		{"abc/**", "x/abc/y", false, false},
+		// `/**/` matches zero or more directories
+		{"a/**/b", "a/b", false, true},
+		{"a/**/b", "a/x/b", false, true},
+		{"a/**/b", "a/x/y/b", false, true},
+		{"a/**/b", "a/xb", false, false},
+		// other consecutive asterisks are regular asterisks
+		{"a/**b", "a/xb", false, true},
+		{"a/**b", "a/x/b", false, false},
+		{"**.go", "main.go", false, true},
+		{"**.go", "a/main.go", false, true},
+		// bracket expressions
+		{"*.[oa]", "x.o", false, true},
+		{"*.[oa]", "x.c", false, false},
+		{"*.[!oa]", "x.c", false, true},
+		{"[]x]", "]", false, true},
+		{"[[:digit:]]*", "1x", false, true},
+		{"[a", "[a", false, true},
+		{`[\a]`, "a", false, true},
+		{`[\a]`, `\`, false, false},
+		{`[a\]]`, "]", false, true},
+		// git does not reject invalid patterns, which just never match
+		{"[z-a]", "z", false, false},
+		{"[z-a]", "[z-a]", false, false},
+		// escapes, and characters that are only special to ohmyglob
+		{`\#file`, "#file", false, true},
+		{`\!important`, "!important", false, true},
+		{`\*`, "*", false, true},
+		{`\*`, "x", false, false},
+		{"@(a){b,c}+(d)!e^f", "@(a){b,c}+(d)!e^f", false, true},
+		{"日本*", "日本語", false, true},
+		// trailing spaces are ignored unless escaped
+		{"foo  ", "foo", false, true},
+		{`foo\ `, "foo ", false, true},
+		{`foo\ `, "foo", false, false},
+		// negation reverses what the rule means, but not whether it matches
+		{"!*.a", "lib.a", false, true},
+	} {
+		t.Run("", func(t *testing.T) {
+			if match := ParseRule(test.rule).Match(test.path, test.isDir); match != test.match {
+				t.Errorf("rule %q matching %q (dir: %v) should be %v, but got %v", test.rule, test.path, test.isDir, test.match, match)
+			}
+		})
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	// lines with an empty pattern are skipped, as git does
+	for _, line := range []string{"", "   ", "# comment", "#", "\r", "/", "!/", "!", "//"} {
+		if rule := ParseRule(line); rule != nil {
+			t.Errorf("line %q should not contain a rule, but got %+v", line, rule)
+		}
+	}
+	rule := ParseRule("!build/\r")
+	if rule.Pattern != "!build/" || !rule.Negate || !rule.DirOnly {
+		t.Errorf("unexpected rule %+v", rule)
+	}
+}
+
+func TestIgnored(t *testing.T) {
+	m := New()
+	for dir, file := range map[string]string{
+		"": `
+# exclude everything except directory foo/bar
+/*
+!/foo
+/foo/*
+!/foo/bar
+!.gitignore
+*.log
+`,
+		"foo/bar": `
+!keep.log
+tmp/
+`,
+		"foo/bar/nested": `
+keep.log
+`,
+	} {
+		if err := m.Add(dir, strings.NewReader(file)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, test := range []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"", true, false},
+		{"README", false, true},
+		{"src", true, true},
+		{"src/main.go", false, true},
+		{".gitignore", false, false},
+		{"foo", true, false},
+		{"foo/baz", true, true},
+		{"foo/baz/x", false, true},
+		{"foo/bar", true, false},
+		{"foo/bar/x.go", false, false},
+		{"foo/bar/x.log", false, true},
+		{"foo/bar/keep.log", false, false},
+		{"foo/bar/a/keep.log", false, false},
+		{"foo/bar/nested/keep.log", false, true},
+		{"foo/bar/tmp", true, true},
+		{"foo/bar/tmp", false, false},
+		// a file cannot be re-included if its directory is excluded
+		{"foo/bar/tmp/keep.log", false, true},
+		{"./foo/bar/x.go", false, false},
+		{"/foo/baz", true, true},
+	} {
+		t.Run("", func(t *testing.T) {
+			if ignored := m.Ignored(test.path, test.isDir); ignored != test.ignored {
+				t.Errorf("%q (dir: %v) should be ignored: %v, but got %v", test.path, test.isDir, test.ignored, ignored)
+			}
+		})
+	}
+
+	m = New()
+	if err := m.Add("x", strings.NewReader("ok\n[z-a]\n/\n")); err != nil {
+		t.Errorf("lines that git accepts should not fail, but got %v", err)
+	}
+	if !m.Ignored("x/ok", false) || m.Ignored("x/z", false) {
+		t.Error("x/ok should be ignored, and x/z should not")
+	}
+	if err := m.Add("y", iotest.ErrReader(errors.New("boom"))); err == nil || !strings.Contains(err.Error(), "y/.gitignore") {
+		t.Errorf("an unreadable ignore file should fail with its path, but got %v", err)
+	}
+}
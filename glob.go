@@ -1,7 +1,11 @@
 package glob
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 	"unicode/utf8"
@@ -10,11 +14,82 @@ import (
 
 	"github.com/pachyderm/ohmyglob/compiler"
 	"github.com/pachyderm/ohmyglob/syntax"
+	"github.com/pachyderm/ohmyglob/syntax/ast"
 )
 
 // Glob represents compiled glob pattern.
 type Glob struct {
-	r *regexp2.Regexp
+	r       *regexp2.Regexp
+	pattern string
+	names   []string
+	tree    *ast.Node
+	opts    Options
+
+	// search is only compiled if one of the Find methods is used
+	search *lazyRegexp
+	// segments is only compiled if CouldMatchUnder is used
+	segments *segmentMatcher
+	// deadlined holds copies of r for matches that must stop at the deadline of their context
+	deadlined *regexpPool
+	// templates holds the template last used with AppendReplace, already parsed
+	templates *templateCache
+}
+
+// lazyRegexp is a regexp2.Regexp that is compiled the first time it is needed
+type lazyRegexp struct {
+	once sync.Once
+	r    *regexp2.Regexp
+	err  error
+}
+
+func (l *lazyRegexp) get(compile func() (*regexp2.Regexp, error)) (*regexp2.Regexp, error) {
+	l.once.Do(func() {
+		l.r, l.err = compile()
+	})
+	return l.r, l.err
+}
+
+// regexpPool holds copies of a compiled regexp, so that each can be given its own MatchTimeout for a single match.
+// regexp2 reads the timeout from the Regexp itself, and has no other way of stopping a match
+type regexpPool struct {
+	regex string
+	pool  sync.Pool
+}
+
+func newRegexpPool(r *regexp2.Regexp) *regexpPool {
+	return &regexpPool{regex: r.String()}
+}
+
+// get returns a copy of the regexp that gives up after timeout
+func (p *regexpPool) get(timeout time.Duration) *regexp2.Regexp {
+	r, _ := p.pool.Get().(*regexp2.Regexp)
+	if r == nil {
+		// the same expression has already compiled, so this cannot fail
+		r = regexp2.MustCompile(p.regex, 0)
+	}
+	r.MatchTimeout = timeout
+	return r
+}
+
+func (p *regexpPool) put(r *regexp2.Regexp) {
+	p.pool.Put(r)
+}
+
+// DefaultMatchTimeout is the MatchTimeout used when none is given in Options.
+// If it takes more than 5 minutes to match a glob, something is very wrong.
+const DefaultMatchTimeout = time.Minute * 5
+
+// Options holds the settings that control how a pattern is compiled and matched.
+// The zero value gives the same behaviour as Compile without any separators.
+type Options struct {
+	// Separators are the characters (typically the path directory char: `/` or `\`)
+	// that `*`, `?` and `!(...)` will not match
+	Separators []rune
+	// IgnoreCase makes the pattern match without regard to letter case
+	IgnoreCase bool
+	// MatchTimeout is the longest a single match may run before it is abandoned with ErrMatchBudget.
+	// If it is zero, DefaultMatchTimeout is used.
+	MatchTimeout time.Duration
 }
 
 // Compile creates Glob for given pattern and strings (if any present after pattern) as separators.
@@ -31,6 +106,8 @@ type Glob struct {
 //                    character class (must be non-empty)
 //        `{` pattern-list `}`
 //                    pattern alternatives
+//        `{` sequence `}`
+//                    matches each value of the sequence
 //        c           matches character c (c != `*`, `**`, `?`, `\`, `[`, `{`, `}`)
 //        `\` c       matches character c
 //
@@ -43,6 +120,12 @@ type Glob struct {
 //        pattern { `,` pattern }
 //                    comma-separated (without spaces) patterns
 //
+//    sequence:
+//        start `..` end [ `..` step ]
+//                    integers (e.g. `{1..10}`, `{0..100..5}`) or single letters (e.g. `{a..f}`),
+//                    counting down if end < start; if start or end has a leading zero (e.g. `{01..12}`),
+//                    every value is zero-padded to the same width
+//
 //    extended-glob:
 //        `(` { `|` pattern } `)`
 //        `@(` { `|` pattern } `)`
@@ -56,13 +139,54 @@ type Glob struct {
 //        `!(` { `|` pattern } `)`
 //                    match and capture anything except one of the pipe-separated subpatterns
 //
+//    named-capture:
+//        q `<` name `>(` { `|` pattern } `)`
+//                    any of the extended-glob forms above (q is one of `@`, `*`, `+`, `?`, `!`),
+//                    with the capture also available by name
+//
 func Compile(pattern string, separators ...rune) (*Glob, error) {
+	return CompileWithOptions(pattern, Options{Separators: separators})
+}
+
+// CompileWithOptions is the same as Compile, except that the separators and any other settings are taken from opts
+func CompileWithOptions(pattern string, opts Options) (*Glob, error) {
+	// the caller may go on to modify the slice of separators, which must not change the compiled Glob
+	opts.Separators = append([]rune(nil), opts.Separators...)
+
 	tree, err := syntax.Parse(pattern)
 	if err != nil {
 		return nil, err
 	}
 
-	regex, err := compiler.Compile(tree, separators)
+	r, err := compileRegexp(tree, opts, false)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{""}
+	for _, c := range compiler.Captures(tree) {
+		names = append(names, c.Value.(ast.Capture).Name)
+	}
+	return &Glob{
+		r:         r,
+		pattern:   pattern,
+		names:     names,
+		tree:      tree,
+		opts:      opts,
+		search:    &lazyRegexp{},
+		segments:  &segmentMatcher{},
+		deadlined: newRegexpPool(r),
+		templates: &templateCache{},
+	}, nil
+}
+
+// compileRegexp converts the glob AST into a regexp2.Regexp
+func compileRegexp(tree *ast.Node, opts Options, unanchored bool) (*regexp2.Regexp, error) {
+	regex, err := compiler.CompileWithOptions(tree, compiler.Options{
+		Separators: opts.Separators,
+		IgnoreCase: opts.IgnoreCase,
+		Unanchored: unanchored,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -70,8 +194,11 @@ func Compile(pattern string, separators ...rune) (*Glob, error) {
 	if err != nil {
 		return nil, err
 	}
-	r.MatchTimeout = time.Minute * 5 // if it takes more than 5minutes to match a glob, something is very wrong
-	return &Glob{r: r}, nil
+	r.MatchTimeout = opts.MatchTimeout
+	if r.MatchTimeout <= 0 {
+		r.MatchTimeout = DefaultMatchTimeout
+	}
+	return r, nil
 }
 
 // MustCompile is the same as Compile, except that if Compile returns error, this will panic
@@ -83,25 +210,151 @@ func MustCompile(pattern string, separators ...rune) *Glob {
 	return g
 }
 
+// MustCompileWithOptions is the same as CompileWithOptions, except that if CompileWithOptions returns error, this will panic
+func MustCompileWithOptions(pattern string, opts Options) *Glob {
+	g, err := CompileWithOptions(pattern, opts)
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+// ErrMatchTimeout is returned when a match is abandoned because the context it was run with is done
+var ErrMatchTimeout = errors.New("glob: match timed out")
+
+// ErrMatchBudget is returned when a match runs for longer than the MatchTimeout the Glob was compiled with
+var ErrMatchBudget = errors.New("glob: match budget exhausted")
+
+// matchError wraps the underlying reason a match was abandoned, while still matching target with errors.Is
+type matchError struct {
+	target error
+	err    error
+}
+
+func (e *matchError) Error() string {
+	return fmt.Sprintf("%v: %v", e.target, e.err)
+}
+
+func (e *matchError) Is(target error) bool {
+	return target == e.target
+}
+
+func (e *matchError) Unwrap() error {
+	return e.err
+}
+
+// runDeadline calls f with the compiled pattern as run does, except that if ctx has a deadline before the MatchTimeout of the Glob,
+// f is given a copy of the regexp that times out at the deadline, so an abandoned f stops soon after it is abandoned
+func (g *Glob) runDeadline(ctx context.Context, f func(r *regexp2.Regexp) error) error {
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) >= g.r.MatchTimeout {
+		return run(ctx, func() error {
+			return f(g.r)
+		})
+	}
+	err := run(ctx, func() error {
+		r := g.deadlined.get(time.Until(deadline))
+		defer g.deadlined.put(r)
+		return f(r)
+	})
+	if errors.Is(err, ErrMatchBudget) {
+		// the copy gave up at the deadline, which ctx may not have noticed yet
+		return &matchError{ErrMatchTimeout, context.DeadlineExceeded}
+	}
+	return err
+}
+
+// run calls f, which should perform a single regexp2 operation, and returns early if ctx is done first.
+// regexp2 has no way of interrupting a match, so an abandoned f keeps running in the background
+// until it completes or reaches the MatchTimeout of the regexp it uses.
+func run(ctx context.Context, f func() error) error {
+	if ctx.Done() == nil {
+		// the context can never be cancelled, so there is no need for a goroutine
+		if err := f(); err != nil {
+			// regexp2 only returns errors when a match exceeds its MatchTimeout
+			return &matchError{ErrMatchBudget, err}
+		}
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return &matchError{ErrMatchTimeout, err}
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- f()
+	}()
+	select {
+	case err := <-errc:
+		if err != nil {
+			return &matchError{ErrMatchBudget, err}
+		}
+		return nil
+	case <-ctx.Done():
+		return &matchError{ErrMatchTimeout, ctx.Err()}
+	}
+}
+
 // Match tests the fixture against the compiled pattern, and return true for a match
 func (g *Glob) Match(fixture string) bool {
-	m, err := g.r.MatchString(fixture)
+	m, err := g.MatchContext(context.Background(), fixture)
 	if err != nil {
-		// this is taking longer than 5 minutes, so something is seriously wrong
+		// the match budget has been exhausted, so something is seriously wrong
 		panic(err)
 	}
 	return m
 }
 
+// MatchContext is the same as Match, except that it gives up and returns ErrMatchTimeout if ctx is done before matching completes,
+// or ErrMatchBudget if matching takes longer than the MatchTimeout of the Glob.
+// If ctx can be cancelled, matching runs in a separate goroutine, which stops at the deadline of ctx if it has one.
+// Cancelling ctx without a deadline does not stop it: it keeps running in the background until it completes
+// or reaches the MatchTimeout (DefaultMatchTimeout, 5 minutes, unless set), so give ctx a deadline,
+// or compile with a lower Options.MatchTimeout, when matching untrusted patterns or fixtures
+func (g *Glob) MatchContext(ctx context.Context, fixture string) (bool, error) {
+	var m bool
+	if err := g.runDeadline(ctx, func(r *regexp2.Regexp) (err error) {
+		m, err = r.MatchString(fixture)
+		return err
+	}); err != nil {
+		return false, err
+	}
+	return m, nil
+}
+
+// find runs the compiled pattern on the fixture, returning nil if it does not match
+func (g *Glob) find(ctx context.Context, fixture string) (*regexp2.Match, error) {
+	var m *regexp2.Match
+	if err := g.runDeadline(ctx, func(r *regexp2.Regexp) (err error) {
+		m, err = r.FindStringMatch(fixture)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Capture returns the list of subexpressions captured while testing the fixture against the compiled pattern
 func (g *Glob) Capture(fixture string) []string {
-	m, err := g.r.FindStringMatch(fixture)
+	captures, err := g.CaptureContext(context.Background(), fixture)
 	if err != nil {
-		// this is taking longer than 5 minutes, so something is seriously wrong
+		// the match budget has been exhausted, so something is seriously wrong
 		panic(err)
 	}
+	return captures
+}
+
+// CaptureContext is the same as Capture, except that it gives up and returns ErrMatchTimeout if ctx is done before matching completes,
+// or ErrMatchBudget if matching takes longer than the MatchTimeout of the Glob.
+// As with MatchContext, a match that is given up on without a deadline keeps running in a background goroutine
+// until it completes or reaches the MatchTimeout
+func (g *Glob) CaptureContext(ctx context.Context, fixture string) ([]string, error) {
+	m, err := g.find(ctx, fixture)
+	if err != nil {
+		return nil, err
+	}
 	if m == nil {
-		return nil
+		return nil, nil
 	}
 	groups := m.Groups()
 	captures := make([]string, 0, len(groups))
@@ -109,16 +362,247 @@ func (g *Glob) Capture(fixture string) []string {
 	for _, gp := range groups {
 		captures = append(captures, gp.Capture.String())
 	}
+	return captures, nil
+}
+
+// CaptureAll returns every string captured by each subexpression while testing the fixture against the compiled pattern,
+// indexed in the same way as the result of Capture.
+// Where Capture only returns the last iteration of a group that was repeated, e.g. the `@(*)` in `+(@(*)/)`,
+// CaptureAll returns them all, in the order that they appear in the fixture. A group that did not take part in the match has no entries.
+// If the fixture does not match, CaptureAll returns nil
+func (g *Glob) CaptureAll(fixture string) [][]string {
+	m, err := g.find(context.Background(), fixture)
+	if err != nil {
+		// the match budget has been exhausted, so something is seriously wrong
+		panic(err)
+	}
+	if m == nil {
+		return nil
+	}
+	groups := m.Groups()
+	captures := make([][]string, 0, len(groups))
+
+	for _, gp := range groups {
+		var all []string
+		for _, c := range gp.Captures {
+			all = append(all, c.String())
+		}
+		captures = append(captures, all)
+	}
 	return captures
 }
 
+// CaptureIndex returns the start and end byte offsets in the fixture of each subexpression captured while testing it against the compiled pattern,
+// indexed in the same way as the result of Capture.
+// A group that did not take part in the match has offsets of -1, which distinguishes it from a group that matched the empty string.
+// If the fixture does not match, CaptureIndex returns nil
+func (g *Glob) CaptureIndex(fixture string) [][2]int {
+	m, err := g.find(context.Background(), fixture)
+	if err != nil {
+		// the match budget has been exhausted, so something is seriously wrong
+		panic(err)
+	}
+	if m == nil {
+		return nil
+	}
+	offsets := byteOffsets(fixture)
+	groups := m.Groups()
+	indices := make([][2]int, 0, len(groups))
+
+	for _, gp := range groups {
+		if len(gp.Captures) == 0 {
+			indices = append(indices, [2]int{-1, -1})
+			continue
+		}
+		indices = append(indices, [2]int{offsets[gp.Index], offsets[gp.Index+gp.Length]})
+	}
+	return indices
+}
+
+// byteOffsets maps the index of each rune in s, as used by regexp2, to its byte offset in s.
+// There is an extra entry at the end for len(s), so that the end of a capture can be looked up too
+func byteOffsets(s string) []int {
+	offsets := make([]int, 0, len(s)+1)
+	for i := range s {
+		offsets = append(offsets, i)
+	}
+	return append(offsets, len(s))
+}
+
+// runeBuffer holds a byte slice decoded into runes for regexp2, along with the byte offset of each rune
+type runeBuffer struct {
+	runes   []rune
+	offsets []int
+}
+
+// runeBuffers are reused between calls, so that matching byte slices does not allocate a new rune slice every time
+var runeBuffers = sync.Pool{
+	New: func() interface{} {
+		return new(runeBuffer)
+	},
+}
+
+func (b *runeBuffer) decode(s []byte) {
+	b.runes, b.offsets = b.runes[:0], b.offsets[:0]
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRune(s[i:])
+		b.runes = append(b.runes, r)
+		b.offsets = append(b.offsets, i)
+		i += size
+	}
+	b.offsets = append(b.offsets, len(s))
+}
+
+// MatchBytes is the same as Match, except that the fixture is a byte slice.
+// The fixture is decoded into a reused buffer, rather than being converted to a string
+func (g *Glob) MatchBytes(fixture []byte) bool {
+	buf := runeBuffers.Get().(*runeBuffer)
+	defer runeBuffers.Put(buf)
+	buf.decode(fixture)
+
+	m, err := g.r.MatchRunes(buf.runes)
+	if err != nil {
+		// the match budget has been exhausted, so something is seriously wrong
+		panic(&matchError{ErrMatchBudget, err})
+	}
+	return m
+}
+
+// CaptureBytes is the same as Capture, except that the fixture is a byte slice.
+// The captured subexpressions are slices of the fixture, rather than copies of it
+func (g *Glob) CaptureBytes(fixture []byte) [][]byte {
+	buf := runeBuffers.Get().(*runeBuffer)
+	defer runeBuffers.Put(buf)
+	buf.decode(fixture)
+
+	m, err := g.r.FindRunesMatch(buf.runes)
+	if err != nil {
+		// the match budget has been exhausted, so something is seriously wrong
+		panic(&matchError{ErrMatchBudget, err})
+	}
+	if m == nil {
+		return nil
+	}
+
+	groups := m.Groups()
+	captures := make([][]byte, 0, len(groups))
+	for _, gp := range groups {
+		start, end := buf.offsets[gp.Index], buf.offsets[gp.Index+gp.Length]
+		captures = append(captures, fixture[start:end:end])
+	}
+	return captures
+}
+
+// Pattern returns the source text that the Glob was compiled from
+func (g *Glob) Pattern() string {
+	return g.pattern
+}
+
+// Separators returns the separators that the Glob was compiled with
+func (g *Glob) Separators() []rune {
+	return append([]rune(nil), g.opts.Separators...)
+}
+
+// Options returns the full set of Options that the Glob was compiled with
+func (g *Glob) Options() Options {
+	opts := g.opts
+	opts.Separators = g.Separators()
+	return opts
+}
+
+// Regexp returns the regular expression that the pattern was compiled to
+func (g *Glob) Regexp() string {
+	return g.r.String()
+}
+
+// NumCaptures returns the number of capture groups in the pattern, not counting the whole match
+func (g *Glob) NumCaptures() int {
+	return len(g.names) - 1
+}
+
+// CaptureGroup describes one of the capture groups in a pattern
+type CaptureGroup struct {
+	// Index is the position of the group in the result of Capture
+	Index int
+	// Name is the name of the group, or the empty string if it is unnamed
+	Name string
+	// Quantifier is the extended glob operator of the group: one of `@`, `*`, `+`, `?` or `!`
+	Quantifier string
+	// Start and End are the byte offsets of the group in the pattern, including its operator and parentheses
+	Start, End int
+}
+
+// CaptureGroups describes each of the capture groups in the pattern, in the same order as the result of Capture (without the whole match)
+func (g *Glob) CaptureGroups() []CaptureGroup {
+	var groups []CaptureGroup
+	for i, node := range compiler.Captures(g.tree) {
+		c := node.Value.(ast.Capture)
+		end := c.End
+		if end < c.Start {
+			// the group was never closed, so it runs to the end of the pattern
+			end = len(g.pattern)
+		}
+		groups = append(groups, CaptureGroup{
+			Index:      i + 1,
+			Name:       c.Name,
+			Quantifier: c.Quantifier,
+			Start:      c.Start,
+			End:        end,
+		})
+	}
+	return groups
+}
+
+// SubexpNames returns the names of the capture groups in the pattern, indexed in the same way as the result of Capture.
+// The name of the whole match (index 0), and of any unnamed group, is the empty string
+func (g *Glob) SubexpNames() []string {
+	return g.names
+}
+
+// SubexpIndex returns the index of the capture group with the given name, or -1 if there is no such group
+func (g *Glob) SubexpIndex(name string) int {
+	return subexpIndex(g.names, name)
+}
+
+func subexpIndex(names []string, name string) int {
+	if name == "" {
+		return -1
+	}
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// CaptureMap returns the named subexpressions captured while testing the fixture against the compiled pattern, keyed by name.
+// If the fixture does not match, CaptureMap returns nil
+func (g *Glob) CaptureMap(fixture string) map[string]string {
+	return captureMap(g.names, g.Capture(fixture))
+}
+
+func captureMap(names []string, captures []string) map[string]string {
+	if captures == nil {
+		return nil
+	}
+	m := make(map[string]string)
+	for i, name := range names {
+		if name != "" && i < len(captures) {
+			m[name] = captures[i]
+		}
+	}
+	return m
+}
+
 // The code for the extract function is based on the extract function from https://golang.org/src/regexp/regexp.go
 // Additionally, the Replace function is based on the expand function from https://golang.org/src/regexp/regexp.go
-func extract(str string) (int, string, bool) {
+// Braced references may also be capture group names, e.g. ${year}, in which case num is -1
+func extract(str string) (string, int, string, bool) {
 	var name, rest string
 	var num int
 	if len(str) < 2 || str[0] != '$' {
-		return num, rest, false
+		return name, num, rest, false
 	}
 	brace := false
 	if str[1] == '{' {
@@ -130,20 +614,20 @@ func extract(str string) (int, string, bool) {
 	i := 0
 	for i < len(str) {
 		rune, size := utf8.DecodeRuneInString(str[i:])
-		if !unicode.IsDigit(rune) {
+		if !unicode.IsDigit(rune) && (!brace || (rune != '_' && !unicode.IsLetter(rune))) {
 			break
 		}
 		i += size
 	}
 	if i == 0 {
 		// empty name is not okay
-		return num, rest, false
+		return name, num, rest, false
 	}
 	name = str[:i]
 	if brace {
 		if i >= len(str) || str[i] != '}' {
 			// missing closing brace
-			return num, rest, false
+			return name, num, rest, false
 		}
 		i++
 	}
@@ -163,41 +647,146 @@ func extract(str string) (int, string, bool) {
 	}
 
 	rest = str[i:]
-	return num, rest, true
+	return name, num, rest, true
 }
 
-// Replace runs the compiled pattern on the given fixture, and then replaces any instance of $n (or ${n}) in the template with the nth capture group
+// Replace runs the compiled pattern on the given fixture, and then replaces any instance of $n (or ${n}) in the template with the nth capture group.
+// Named capture groups can also be referred to as ${name}; a ${name} that is not the name of a capture group is left as it is
 func (g *Glob) Replace(fixture, template string) string {
-	result := ""
-	match := g.Capture(fixture)
+	return expand(template, g.names, g.Capture(fixture))
+}
+
+// ReplaceContext is the same as Replace, except that it gives up and returns ErrMatchTimeout if ctx is done before matching completes,
+// or ErrMatchBudget if matching takes longer than the MatchTimeout of the Glob.
+// As with MatchContext, a match that is given up on without a deadline keeps running in a background goroutine
+// until it completes or reaches the MatchTimeout
+func (g *Glob) ReplaceContext(ctx context.Context, fixture, template string) (string, error) {
+	match, err := g.CaptureContext(ctx, fixture)
+	if err != nil {
+		return "", err
+	}
+	return expand(template, g.names, match), nil
+}
+
+// AppendReplace is the same as Replace, except that the fixture is a byte slice, and the result is appended to dst.
+// The template is only parsed again if it is different from the one used last time
+func (g *Glob) AppendReplace(dst, fixture []byte, template string) []byte {
+	return appendPiecesBytes(dst, g.templates.parse(template, g.names), g.CaptureBytes(fixture))
+}
+
+// ErrNoMatch is returned when there is nothing to replace, because the fixture does not match the pattern
+var ErrNoMatch = errors.New("glob: fixture does not match pattern")
+
+// ReplaceFunc runs the compiled pattern on the given fixture, and returns the result of calling fn with the captured subexpressions
+// (in the same form as the result of Capture). If the fixture does not match, fn is not called and ErrNoMatch is returned
+func (g *Glob) ReplaceFunc(fixture string, fn func(captures []string) (string, error)) (string, error) {
+	captures := g.Capture(fixture)
+	if captures == nil {
+		return "", ErrNoMatch
+	}
+	return fn(captures)
+}
+
+// ReplaceFuncMap is the same as ReplaceFunc, except that fn is called with the named subexpressions (in the same form as the result of CaptureMap)
+func (g *Glob) ReplaceFuncMap(fixture string, fn func(captures map[string]string) (string, error)) (string, error) {
+	captures := g.CaptureMap(fixture)
+	if captures == nil {
+		return "", ErrNoMatch
+	}
+	return fn(captures)
+}
+
+// expand replaces any instance of $n (or ${n}) in the template with the nth entry in match,
+// and any instance of ${name} with the entry in match for the group with that name
+func expand(template string, names []string, match []string) string {
+	pieces, _ := parseTemplate(template, names, false)
+	return string(appendPieces(nil, pieces, match))
+}
+
+// piece is part of a parsed replacement template; either literal text, or a reference to a capture group
+type piece struct {
+	text  string
+	group int // -1 for literal text
+}
+
+// parseTemplate splits the template into literal text and references to capture groups.
+// If strict is set, a reference to a group that does not exist is an error, otherwise it is dropped
+func parseTemplate(template string, names []string, strict bool) ([]piece, error) {
+	var pieces []piece
+	literal := func(text string) {
+		if text == "" {
+			return
+		}
+		if n := len(pieces); n > 0 && pieces[n-1].group < 0 {
+			pieces[n-1].text += text
+			return
+		}
+		pieces = append(pieces, piece{text: text, group: -1})
+	}
 	for len(template) > 0 {
 		i := strings.Index(template, "$")
 		if i < 0 {
 			break
 		}
-		result += template[:i]
+		literal(template[:i])
 		template = template[i:]
 		if len(template) > 1 && template[1] == '$' {
 			// Treat $$ as $.
-			result += "$"
+			literal("$")
 			template = template[2:]
 			continue
 		}
-		num, rest, ok := extract(template)
+		name, num, rest, ok := extract(template)
 
 		if !ok {
 			// Malformed; treat $ as raw text.
-			result += "$"
+			literal("$")
 			template = template[1:]
 			continue
 		}
+		ref := template[:len(template)-len(rest)]
 		template = rest
-		if num < len(match) {
-			result += match[num]
+		if num < 0 {
+			num = subexpIndex(names, name)
+		}
+		if num < 0 || num >= len(names) {
+			if strict {
+				return nil, fmt.Errorf("glob: template reference %v does not match a capture group", ref)
+			}
+			if num < 0 && !unicode.IsDigit(rune(name[0])) {
+				// an unknown name is left as it is, as it was before captures could be named
+				literal(ref)
+			}
+			continue
+		}
+		pieces = append(pieces, piece{group: num})
+	}
+	literal(template)
+	return pieces, nil
+}
+
+// appendPieces appends the parsed template to dst, filling in the references to capture groups from match
+func appendPieces(dst []byte, pieces []piece, match []string) []byte {
+	for _, p := range pieces {
+		if p.group < 0 {
+			dst = append(dst, p.text...)
+		} else if p.group < len(match) {
+			dst = append(dst, match[p.group]...)
+		}
+	}
+	return dst
+}
+
+// appendPiecesBytes is the same as appendPieces, except that match is made up of byte slices
+func appendPiecesBytes(dst []byte, pieces []piece, match [][]byte) []byte {
+	for _, p := range pieces {
+		if p.group < 0 {
+			dst = append(dst, p.text...)
+		} else if p.group < len(match) {
+			dst = append(dst, match[p.group]...)
 		}
 	}
-	result += template
-	return result
+	return dst
 }
 
 // QuoteMeta returns a string that quotes all glob pattern meta characters
@@ -1,8 +1,13 @@
 package glob
 
 import (
+	"context"
+	"errors"
 	"regexp"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 )
 
 const (
@@ -176,6 +181,25 @@ func TestGlob(t *testing.T) {
 
 		glob(true, pattern_prefix_suffix, fixture_prefix_suffix_match),
 		glob(false, pattern_prefix_suffix, fixture_prefix_suffix_mismatch),
+
+		glob(true, "shard-{000..127}/*", "shard-042/x", '/'),
+		glob(true, "shard-{000..127}/*", "shard-127/x", '/'),
+		glob(false, "shard-{000..127}/*", "shard-128/x", '/'),
+		glob(false, "shard-{000..127}/*", "shard-42/x", '/'),
+		glob(true, "{1..10}.log", "10.log"),
+		glob(false, "{1..10}.log", "01.log"),
+		glob(true, "{10..1}.log", "7.log"),
+		glob(true, "{0..100..5}", "85"),
+		glob(false, "{0..100..5}", "86"),
+		glob(true, "{-3..3}", "-2"),
+		glob(true, "{a..f}", "c"),
+		glob(false, "{a..f}", "g"),
+		glob(true, "{a..f..2}", "e"),
+		glob(false, "{a..f..2}", "d"),
+		glob(true, "{1..x}", "1..x"),
+		glob(true, "{1..3,x}", "1..3"),
+		glob(true, "!({1..3}).txt", "4.txt"),
+		glob(false, "!({1..3}).txt", "2.txt"),
 	} {
 		t.Run("", func(t *testing.T) {
 			g := MustCompile(test.pattern, test.delimiters...)
@@ -186,10 +210,133 @@ func TestGlob(t *testing.T) {
 					test.pattern, test.match, test.should, result, g.r,
 				)
 			}
+			if result := g.MatchBytes([]byte(test.match)); result != test.should {
+				t.Errorf(
+					"pattern %q matching bytes %q should be %v but got %v\n%s",
+					test.pattern, test.match, test.should, result, g.r,
+				)
+			}
 		})
 	}
 }
 
+func TestCompileWithOptions(t *testing.T) {
+	for _, test := range []struct {
+		pattern string
+		opts    Options
+		match   string
+		should  bool
+	}{
+		{"*.go", Options{}, "a/b.go", true},
+		{"*.go", Options{Separators: []rune{'/'}}, "a/b.go", false},
+		{"*.go", Options{Separators: []rune{'/'}}, "b.go", true},
+		{"*.GO", Options{}, "b.go", false},
+		{"*.GO", Options{IgnoreCase: true}, "b.go", true},
+		{"[A-C]at", Options{IgnoreCase: true}, "bAT", true},
+		{"@(Foo|bar)/*", Options{Separators: []rune{'/'}, IgnoreCase: true}, "BAR/x", true},
+		{"@(Foo|bar)/*", Options{Separators: []rune{'/'}, IgnoreCase: true}, "BAR/x/y", false},
+	} {
+		t.Run("", func(t *testing.T) {
+			g := MustCompileWithOptions(test.pattern, test.opts)
+			result := g.Match(test.match)
+			if result != test.should {
+				t.Errorf(
+					"pattern %q with options %+v matching %q should be %v but got %v\n%s",
+					test.pattern, test.opts, test.match, test.should, result, g.r,
+				)
+			}
+		})
+	}
+}
+
+func TestMatchContext(t *testing.T) {
+	// the budget stops any abandoned matches from running in the background for long
+	g := MustCompileWithOptions("+(+(a|aa))b", Options{MatchTimeout: time.Second})
+	fixture := strings.Repeat("a", 64) + "c"
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := g.MatchContext(cancelled, "ab"); !errors.Is(err, ErrMatchTimeout) || !errors.Is(err, context.Canceled) {
+		t.Errorf("matching with a cancelled context should fail with ErrMatchTimeout, but got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := g.MatchContext(ctx, fixture); !errors.Is(err, ErrMatchTimeout) {
+		t.Errorf("pathological match should fail with ErrMatchTimeout, but got %v", err)
+	}
+	if _, err := g.CaptureContext(ctx, fixture); !errors.Is(err, ErrMatchTimeout) {
+		t.Errorf("pathological capture should fail with ErrMatchTimeout, but got %v", err)
+	}
+	if _, err := g.ReplaceContext(ctx, fixture, "$1"); !errors.Is(err, ErrMatchTimeout) {
+		t.Errorf("pathological replace should fail with ErrMatchTimeout, but got %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if m, err := g.MatchContext(ctx, "aab"); err != nil || !m {
+		t.Errorf("pattern %q matching %q should be true but got %v, %v", "+(+(a|aa))b", "aab", m, err)
+	}
+	if r, err := g.ReplaceContext(ctx, "aab", "$1"); err != nil || r != "aa" {
+		t.Errorf("replacement should have returned %q, but got %q, %v", "aa", r, err)
+	}
+}
+
+func TestMatchDeadline(t *testing.T) {
+	// without the deadline, the default budget would keep the abandoned match running for minutes
+	g := MustCompile("+(+(a|aa))b")
+	fixture := strings.Repeat("a", 64) + "c"
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := g.MatchContext(ctx, fixture); !errors.Is(err, ErrMatchTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("pathological match should fail with ErrMatchTimeout, but got %v", err)
+	}
+	for start := time.Now(); runtime.NumGoroutine() > before; time.Sleep(time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("the abandoned match should have stopped at the deadline, but is still running")
+		}
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if m, err := g.MatchContext(ctx, "aab"); err != nil || !m {
+		t.Errorf("pattern %q matching %q should be true but got %v, %v", "+(+(a|aa))b", "aab", m, err)
+	}
+}
+
+func TestMatchBudget(t *testing.T) {
+	g := MustCompileWithOptions("+(+(a|aa))b", Options{MatchTimeout: 10 * time.Millisecond})
+	fixture := strings.Repeat("a", 64) + "c"
+
+	_, err := g.MatchContext(context.Background(), fixture)
+	if !errors.Is(err, ErrMatchBudget) || errors.Is(err, ErrMatchTimeout) {
+		t.Errorf("pathological match should fail with ErrMatchBudget, but got %v", err)
+	}
+	if _, err := g.CaptureContext(context.Background(), fixture); !errors.Is(err, ErrMatchBudget) {
+		t.Errorf("pathological capture should fail with ErrMatchBudget, but got %v", err)
+	}
+	if m, err := g.MatchContext(context.Background(), "aab"); err != nil || !m {
+		t.Errorf("pattern %q matching %q should be true but got %v, %v", "+(+(a|aa))b", "aab", m, err)
+	}
+}
+
+func TestBytes(t *testing.T) {
+	g := MustCompile("@(*)/@(*).go", '/')
+	fixture := []byte("dir/main.go")
+	// invalid UTF-8 is matched as utf8.RuneError, as it is in a string
+	if captures := g.CaptureBytes([]byte("\xff/\xfe.go")); len(captures) != 3 || string(captures[1]) != "\xff" || string(captures[2]) != "\xfe" {
+		t.Errorf("captures from invalid UTF-8 should be slices of the fixture, but got %q", captures)
+	}
+	// the template used last time must not be reused for a different one
+	for _, template := range []string{"$2 in $1", "$1/$2", "$2 in $1"} {
+		if result, expected := string(g.AppendReplace(nil, fixture, template)), g.Replace(string(fixture), template); result != expected {
+			t.Errorf("template %q should have appended %q, but got %q", template, expected, result)
+		}
+	}
+}
+
 func TestQuoteMeta(t *testing.T) {
 	for id, test := range []struct {
 		in, out string
@@ -545,3 +692,84 @@ func BenchmarkPrefixSuffixRegexpMismatch(b *testing.B) {
 		_ = m.Match(f)
 	}
 }
+
+const (
+	pattern_capture  = "name=@(*)/year=@(*)/@(*).dat"
+	template_capture = "$3 of $1 from $2"
+	fixture_capture  = "name=adele/year=2016/photos.dat"
+)
+
+func BenchmarkCaptureGlobMatch(b *testing.B) {
+	m, _ := Compile(pattern_capture, '/')
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_ = m.Match(fixture_capture)
+	}
+}
+func BenchmarkCaptureGlobMatchBytes(b *testing.B) {
+	m, _ := Compile(pattern_capture, '/')
+	f := []byte(fixture_capture)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_ = m.MatchBytes(f)
+	}
+}
+func BenchmarkCaptureGlobCapture(b *testing.B) {
+	m, _ := Compile(pattern_capture, '/')
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_ = m.Capture(fixture_capture)
+	}
+}
+func BenchmarkCaptureGlobCaptureBytes(b *testing.B) {
+	m, _ := Compile(pattern_capture, '/')
+	f := []byte(fixture_capture)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_ = m.CaptureBytes(f)
+	}
+}
+func BenchmarkCaptureGlobReplace(b *testing.B) {
+	m, _ := Compile(pattern_capture, '/')
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_ = m.Replace(fixture_capture, template_capture)
+	}
+}
+func BenchmarkCaptureGlobAppendReplace(b *testing.B) {
+	m, _ := Compile(pattern_capture, '/')
+	f := []byte(fixture_capture)
+	var dst []byte
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		dst = m.AppendReplace(dst[:0], f, template_capture)
+	}
+}
+func BenchmarkCaptureGlobTemplateAppendReplace(b *testing.B) {
+	m, _ := Compile(pattern_capture, '/')
+	t := m.MustCompileTemplate(template_capture)
+	f := []byte(fixture_capture)
+	var dst []byte
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		dst, _ = t.AppendReplace(dst[:0], f)
+	}
+}
+func BenchmarkCaptureRegexpReplace(b *testing.B) {
+	m := regexp.MustCompile(`^name=([^/]*)/year=([^/]*)/([^/]*)\.dat$`)
+	f := []byte(fixture_capture)
+	t := []byte(template_capture)
+	var dst []byte
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		dst = m.Expand(dst[:0], t, f, m.FindSubmatchIndex(f))
+	}
+}
@@ -0,0 +1,299 @@
+package glob
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dlclark/regexp2"
+
+	"github.com/pachyderm/ohmyglob/syntax/ast"
+)
+
+// GlobSet matches a string against many globs at once.
+// Globs are indexed by the literal text that they must match (the whole string, or its prefix or suffix),
+// so that only the globs that could possibly match a string are actually tested against it.
+// Large groups of globs that share an index entry, and the globs that could not be indexed, are tested with combined regexps (see globGroup)
+type GlobSet struct {
+	globs []*Glob
+
+	exact      map[string][]int
+	prefixes   map[string]*globGroup
+	prefixLens []int
+	suffixes   map[string]*globGroup
+	suffixLens []int
+	// others are the globs that could not be indexed, which are tested against every string
+	others *globGroup
+}
+
+// NewGlobSet creates a GlobSet from already compiled globs. The indices returned by Matches are positions in globs
+func NewGlobSet(globs ...*Glob) *GlobSet {
+	s := &GlobSet{
+		globs:    globs,
+		exact:    make(map[string][]int),
+		prefixes: make(map[string]*globGroup),
+		suffixes: make(map[string]*globGroup),
+	}
+	prefixes := make(map[string][]int)
+	suffixes := make(map[string][]int)
+	var others []int
+	for i, g := range globs {
+		if g.opts.IgnoreCase {
+			// literal text cannot be looked up without regard to case
+			others = append(others, i)
+			continue
+		}
+		prefix, complete := g.LiteralPrefix()
+		if complete {
+			s.exact[prefix] = append(s.exact[prefix], i)
+			continue
+		}
+		if prefix != "" {
+			if _, ok := prefixes[prefix]; !ok {
+				s.prefixLens = appendLen(s.prefixLens, len(prefix))
+			}
+			prefixes[prefix] = append(prefixes[prefix], i)
+			continue
+		}
+		if suffix := literalSuffix(g.tree); suffix != "" {
+			if _, ok := suffixes[suffix]; !ok {
+				s.suffixLens = appendLen(s.suffixLens, len(suffix))
+			}
+			suffixes[suffix] = append(suffixes[suffix], i)
+			continue
+		}
+		others = append(others, i)
+	}
+	for prefix, indices := range prefixes {
+		s.prefixes[prefix] = newGlobGroup(globs, indices)
+	}
+	for suffix, indices := range suffixes {
+		s.suffixes[suffix] = newGlobGroup(globs, indices)
+	}
+	s.others = newGlobGroup(globs, others)
+	return s
+}
+
+// CompileGlobSet compiles each of the patterns with the given separators, and creates a GlobSet from them
+func CompileGlobSet(patterns []string, separators ...rune) (*GlobSet, error) {
+	globs := make([]*Glob, 0, len(patterns))
+	for _, pattern := range patterns {
+		g, err := Compile(pattern, separators...)
+		if err != nil {
+			return nil, err
+		}
+		globs = append(globs, g)
+	}
+	return NewGlobSet(globs...), nil
+}
+
+// Len returns the number of globs in the set
+func (s *GlobSet) Len() int {
+	return len(s.globs)
+}
+
+// Matches returns the indices of all the globs in the set that match the fixture, in increasing order
+func (s *GlobSet) Matches(fixture string) []int {
+	var matches []int
+	s.match(fixture, func(i int) bool {
+		matches = append(matches, i)
+		return true
+	})
+	sort.Ints(matches)
+	return matches
+}
+
+// Match returns true if any of the globs in the set match the fixture
+func (s *GlobSet) Match(fixture string) bool {
+	matched := false
+	s.match(fixture, func(i int) bool {
+		matched = true
+		return false
+	})
+	return matched
+}
+
+// match calls f with the index of each glob that matches the fixture, until f returns false
+func (s *GlobSet) match(fixture string, f func(int) bool) {
+	for _, i := range s.exact[fixture] {
+		// these globs only match exactly this string
+		if !f(i) {
+			return
+		}
+	}
+	for _, n := range s.prefixLens {
+		if n > len(fixture) {
+			break
+		}
+		if g, ok := s.prefixes[fixture[:n]]; ok && !g.match(fixture, f) {
+			return
+		}
+	}
+	for _, n := range s.suffixLens {
+		if n > len(fixture) {
+			break
+		}
+		if g, ok := s.suffixes[fixture[len(fixture)-n:]]; ok && !g.match(fixture, f) {
+			return
+		}
+	}
+	s.others.match(fixture, f)
+}
+
+// minGlobGroup is the most globs that are tested one at a time, rather than with a combined regexp
+const minGlobGroup = 8
+
+// globGroup is a list of globs from a GlobSet that are matched together.
+// A large group is split in half, recursively, and each part is tested with a single regexp that is an alternation of all its globs.
+// Alternatives are tried in order, so the first one to take part in a match is the first glob in the part that matches,
+// which means a part in which nothing matches is ruled out with one regexp match, rather than one for each of its globs,
+// and after a match only the parts that hold the globs after it still need to be tried
+type globGroup struct {
+	globs   []*Glob
+	indices []int
+
+	// left and right are the halves of a large group, and are nil for a small one
+	left, right *globGroup
+
+	// combined is the alternation of every glob in the group, which is compiled the first time it is needed
+	once     sync.Once
+	combined *regexp2.Regexp
+	err      error
+	// starts are the numbers of the capture groups around each alternative
+	starts []int
+}
+
+func newGlobGroup(globs []*Glob, indices []int) *globGroup {
+	g := &globGroup{globs: globs, indices: indices}
+	if len(indices) > minGlobGroup {
+		mid := len(indices) / 2
+		g.left = newGlobGroup(globs, indices[:mid])
+		g.right = newGlobGroup(globs, indices[mid:])
+	}
+	return g
+}
+
+// match calls f with the index of each glob in the group that matches the fixture, in order, returning false if f does
+func (g *globGroup) match(fixture string, f func(int) bool) bool {
+	if g.left == nil {
+		return g.matchEach(fixture, 0, f)
+	}
+	first := g.first(fixture)
+	if first < 0 {
+		return true
+	}
+	if !f(g.indices[first]) {
+		return false
+	}
+	return g.matchFrom(fixture, first+1, f)
+}
+
+// matchFrom is the same as match, except that it only tries the globs from position start onwards
+func (g *globGroup) matchFrom(fixture string, start int, f func(int) bool) bool {
+	switch {
+	case start == 0:
+		return g.match(fixture, f)
+	case start >= len(g.indices):
+		return true
+	case g.left == nil:
+		return g.matchEach(fixture, start, f)
+	case start < len(g.left.indices):
+		return g.left.matchFrom(fixture, start, f) && g.right.match(fixture, f)
+	default:
+		return g.right.matchFrom(fixture, start-len(g.left.indices), f)
+	}
+}
+
+// matchEach tries each glob from position start onwards in turn
+func (g *globGroup) matchEach(fixture string, start int, f func(int) bool) bool {
+	for _, i := range g.indices[start:] {
+		if g.globs[i].Match(fixture) && !f(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// first returns the position in the group of the first glob that matches the fixture, or -1 if none do
+func (g *globGroup) first(fixture string) int {
+	g.once.Do(g.compile)
+	if g.err != nil {
+		// every glob compiled on its own, so this should never happen, but they can still be tried one at a time
+		for k, i := range g.indices {
+			if g.globs[i].Match(fixture) {
+				return k
+			}
+		}
+		return -1
+	}
+	var m *regexp2.Match
+	if err := run(context.Background(), func() (err error) {
+		m, err = g.combined.FindStringMatch(fixture)
+		return err
+	}); err != nil {
+		// the match budget has been exhausted, so something is seriously wrong
+		panic(err)
+	}
+	if m == nil {
+		return -1
+	}
+	for k, n := range g.starts {
+		if gp := m.GroupByNumber(n); gp != nil && len(gp.Captures) > 0 {
+			return k
+		}
+	}
+	return -1
+}
+
+// compile builds the alternation of every glob in the group, with a capture group around each one
+func (g *globGroup) compile() {
+	var buf strings.Builder
+	// each regexp is already anchored, but regexp2 only avoids trying every position in the fixture if the whole alternation is
+	buf.WriteString(`\A(?:`)
+	group := 1
+	timeout := time.Duration(0)
+	for k, i := range g.indices {
+		if k > 0 {
+			buf.WriteByte('|')
+		}
+		// any flags that the regexp starts with only apply inside its group
+		buf.WriteString("(" + g.globs[i].r.String() + ")")
+		g.starts = append(g.starts, group)
+		group += 1 + g.globs[i].NumCaptures()
+		if t := g.globs[i].r.MatchTimeout; t > timeout {
+			timeout = t
+		}
+	}
+	buf.WriteString(`)\z`)
+	g.combined, g.err = regexp2.Compile(buf.String(), 0)
+	if g.err == nil {
+		g.combined.MatchTimeout = timeout
+	}
+}
+
+// appendLen adds n to the sorted list of distinct lengths
+func appendLen(lens []int, n int) []int {
+	i := sort.SearchInts(lens, n)
+	if i < len(lens) && lens[i] == n {
+		return lens
+	}
+	lens = append(lens, 0)
+	copy(lens[i+1:], lens[i:])
+	lens[i] = n
+	return lens
+}
+
+// literalSuffix returns the text at the end of the pattern, after any wildcards, alternatives or captures
+func literalSuffix(tree *ast.Node) string {
+	i := len(tree.Children)
+	for i > 0 && tree.Children[i-1].Kind == ast.KindText {
+		i--
+	}
+	var buf strings.Builder
+	for _, child := range tree.Children[i:] {
+		buf.WriteString(child.Value.(ast.Text).Text)
+	}
+	return buf.String()
+}
@@ -0,0 +1,186 @@
+package glob
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestGlobSet(t *testing.T) {
+	set, err := CompileGlobSet([]string{
+		"src/main.go",
+		"src/*.go",
+		"src/**",
+		"*.go",
+		"**/*_test.go",
+		"{src,pkg}/*.go",
+		"src/main.go",
+		"?rc/*",
+		"src/@(*).go",
+	}, '/')
+	if err != nil {
+		t.Fatal(err)
+	}
+	set = NewGlobSet(append(set.globs, MustCompileWithOptions("SRC/*.GO", Options{Separators: []rune{'/'}, IgnoreCase: true}))...)
+	if set.Len() != 10 {
+		t.Errorf("set should have 10 globs, but has %d", set.Len())
+	}
+
+	for _, test := range []struct {
+		fixture string
+		matches []int
+	}{
+		{"src/main.go", []int{0, 1, 2, 5, 6, 7, 8, 9}},
+		{"src/a_test.go", []int{1, 2, 4, 5, 7, 8, 9}},
+		{"pkg/a.go", []int{5}},
+		{"main.go", []int{3}},
+		{"src/a/b_test.go", []int{2, 4}},
+		{"src", nil},
+		{"", nil},
+	} {
+		t.Run("", func(t *testing.T) {
+			matches := set.Matches(test.fixture)
+			if !reflect.DeepEqual(matches, test.matches) {
+				t.Errorf("%q should match globs %v, but matched %v", test.fixture, test.matches, matches)
+			}
+			if match := set.Match(test.fixture); match != (len(test.matches) > 0) {
+				t.Errorf("%q should match: %v, but got %v", test.fixture, len(test.matches) > 0, match)
+			}
+			// the set must agree with matching each glob in turn
+			var expected []int
+			for i, g := range set.globs {
+				if g.Match(test.fixture) {
+					expected = append(expected, i)
+				}
+			}
+			if !reflect.DeepEqual(matches, expected) {
+				t.Errorf("%q matched globs %v in the set, but %v individually", test.fixture, matches, expected)
+			}
+		})
+	}
+
+	if _, err := CompileGlobSet([]string{"a", "[b"}); err == nil {
+		t.Error("compiling a set with an invalid pattern should fail")
+	}
+}
+
+func TestGlobSetGroups(t *testing.T) {
+	// enough globs that share a suffix, or cannot be indexed, for them to be tested with combined regexps
+	var globs []*Glob
+	for i := 0; i < 40; i++ {
+		globs = append(globs,
+			MustCompile(fmt.Sprintf("*/x%d/*.csv", i%10), '/'),
+			MustCompile(fmt.Sprintf("*x%d*", i%7), '/'),
+			MustCompile(fmt.Sprintf("@(*)/!(x%d)/**", i%5), '/'),
+			MustCompileWithOptions(fmt.Sprintf("*/X%d/**", i%3), Options{Separators: []rune{'/'}, IgnoreCase: true}),
+		)
+	}
+	set := NewGlobSet(globs...)
+
+	for _, fixture := range []string{"a/x1/b.csv", "a/x3/b.txt", "x4", "a/x2/c/d", "a/b/c", "", "a/x9/b.csv", "q/X0/z"} {
+		var expected []int
+		for i, g := range globs {
+			if g.Match(fixture) {
+				expected = append(expected, i)
+			}
+		}
+		if matches := set.Matches(fixture); !reflect.DeepEqual(matches, expected) {
+			t.Errorf("%q matched globs %v in the set, but %v individually", fixture, matches, expected)
+		}
+		if match := set.Match(fixture); match != (len(expected) > 0) {
+			t.Errorf("%q should match: %v, but got %v", fixture, len(expected) > 0, match)
+		}
+	}
+}
+
+func globSetPatterns(n int) []string {
+	patterns := make([]string, 0, n)
+	for i := 0; len(patterns) < n; i++ {
+		switch i % 4 {
+		case 0:
+			patterns = append(patterns, fmt.Sprintf("data/%d/*.csv", i))
+		case 1:
+			patterns = append(patterns, fmt.Sprintf("logs/%d/**", i))
+		case 2:
+			patterns = append(patterns, fmt.Sprintf("**/*.ext%d", i))
+		case 3:
+			patterns = append(patterns, fmt.Sprintf("static/%d.txt", i))
+		}
+	}
+	return patterns
+}
+
+const fixture_globset = "data/4/file.csv"
+
+func benchmarkGlobSetMatches(b *testing.B, n int) {
+	set, _ := CompileGlobSet(globSetPatterns(n), '/')
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = set.Matches(fixture_globset)
+	}
+}
+
+func benchmarkGlobLoopMatches(b *testing.B, n int) {
+	set, _ := CompileGlobSet(globSetPatterns(n), '/')
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var matches []int
+		for j, g := range set.globs {
+			if g.Match(fixture_globset) {
+				matches = append(matches, j)
+			}
+		}
+	}
+}
+
+func BenchmarkGlobSetMatches10(b *testing.B)    { benchmarkGlobSetMatches(b, 10) }
+func BenchmarkGlobSetMatches100(b *testing.B)   { benchmarkGlobSetMatches(b, 100) }
+func BenchmarkGlobSetMatches1000(b *testing.B)  { benchmarkGlobSetMatches(b, 1000) }
+func BenchmarkGlobLoopMatches10(b *testing.B)   { benchmarkGlobLoopMatches(b, 10) }
+func BenchmarkGlobLoopMatches100(b *testing.B)  { benchmarkGlobLoopMatches(b, 100) }
+func BenchmarkGlobLoopMatches1000(b *testing.B) { benchmarkGlobLoopMatches(b, 1000) }
+
+// globSetSharedPatterns are patterns that do not index well: they all share a suffix, or have no literal prefix or suffix at all
+func globSetSharedPatterns(n int) []string {
+	patterns := make([]string, 0, n)
+	for i := 0; len(patterns) < n; i++ {
+		switch i % 2 {
+		case 0:
+			patterns = append(patterns, fmt.Sprintf("*/x%d/*.csv", i))
+		case 1:
+			patterns = append(patterns, fmt.Sprintf("*/y%d/**", i))
+		}
+	}
+	return patterns
+}
+
+// fixture_globset_shared matches one of the shared patterns, so the combined regexps have to find it
+const fixture_globset_shared = "data/x4/file.csv"
+
+func benchmarkGlobSetSharedMatches(b *testing.B, n int) {
+	set, _ := CompileGlobSet(globSetSharedPatterns(n), '/')
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = set.Matches(fixture_globset_shared)
+	}
+}
+
+func benchmarkGlobLoopSharedMatches(b *testing.B, n int) {
+	set, _ := CompileGlobSet(globSetSharedPatterns(n), '/')
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var matches []int
+		for j, g := range set.globs {
+			if g.Match(fixture_globset_shared) {
+				matches = append(matches, j)
+			}
+		}
+	}
+}
+
+func BenchmarkGlobSetSharedMatches10(b *testing.B)    { benchmarkGlobSetSharedMatches(b, 10) }
+func BenchmarkGlobSetSharedMatches100(b *testing.B)   { benchmarkGlobSetSharedMatches(b, 100) }
+func BenchmarkGlobSetSharedMatches1000(b *testing.B)  { benchmarkGlobSetSharedMatches(b, 1000) }
+func BenchmarkGlobLoopSharedMatches10(b *testing.B)   { benchmarkGlobLoopSharedMatches(b, 10) }
+func BenchmarkGlobLoopSharedMatches100(b *testing.B)  { benchmarkGlobLoopSharedMatches(b, 100) }
+func BenchmarkGlobLoopSharedMatches1000(b *testing.B) { benchmarkGlobLoopSharedMatches(b, 1000) }
@@ -0,0 +1,59 @@
+package glob
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIntrospection(t *testing.T) {
+	pattern := "data/@<year>(*)/(a|+(b))/*.csv"
+	g := MustCompile(pattern, '/')
+
+	if g.Pattern() != pattern {
+		t.Errorf("pattern should be %q, but got %q", pattern, g.Pattern())
+	}
+	if !reflect.DeepEqual(g.Separators(), []rune{'/'}) {
+		t.Errorf("separators should be %q, but got %q", []rune{'/'}, g.Separators())
+	}
+	if regex := `\Adata/([^/]*)/(a|((?:b)+))/[^/]*\.csv\z`; g.Regexp() != regex {
+		t.Errorf("regexp should be %q, but got %q", regex, g.Regexp())
+	}
+	if g.NumCaptures() != 3 {
+		t.Errorf("there should be 3 captures, but got %d", g.NumCaptures())
+	}
+
+	groups := []CaptureGroup{
+		{Index: 1, Name: "year", Quantifier: "@", Start: 5, End: 15},
+		{Index: 2, Quantifier: "@", Start: 16, End: 24},
+		{Index: 3, Quantifier: "+", Start: 19, End: 23},
+	}
+	if !reflect.DeepEqual(g.CaptureGroups(), groups) {
+		t.Errorf("capture groups should be %+v, but got %+v", groups, g.CaptureGroups())
+	}
+
+	if groups := MustCompile("a*(b").CaptureGroups(); !reflect.DeepEqual(groups, []CaptureGroup{{Index: 1, Quantifier: "*", Start: 1, End: 4}}) {
+		t.Errorf("unclosed capture group should run to the end of the pattern, but got %+v", groups)
+	}
+}
+
+func TestSeparatorsNotShared(t *testing.T) {
+	seps := []rune{'/'}
+	g, err := CompileWithOptions("a/*/c", Options{Separators: seps})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := MustCompile("a/*/c", '/').String()
+
+	seps[0] = '.'
+	g.Separators()[0] = '.'
+	g.Options().Separators[0] = '.'
+	if !g.CouldMatchUnder("a/b") {
+		t.Error("a/*/c could match under a/b, after modifying the separators it was compiled with")
+	}
+	if g.String() != want {
+		t.Errorf("string should be %q, but got %q after modifying the separators it was compiled with", want, g.String())
+	}
+	if !reflect.DeepEqual(g.Separators(), []rune{'/'}) {
+		t.Errorf("separators should be %q, but got %q", []rune{'/'}, g.Separators())
+	}
+}
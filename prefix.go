@@ -0,0 +1,104 @@
+package glob
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pachyderm/ohmyglob/syntax/ast"
+)
+
+// LiteralPrefix returns the literal text that every string matched by the pattern starts with,
+// with any escapes removed, and complete is true if that text is the only string the pattern matches.
+// Alternatives that share a prefix contribute it, e.g. `{abc,abd}*` has the prefix `ab`.
+// A Glob compiled with IgnoreCase has no literal prefix
+func (g *Glob) LiteralPrefix() (prefix string, complete bool) {
+	if g.opts.IgnoreCase {
+		return "", false
+	}
+	return prefixOf(g.tree)
+}
+
+// Base returns the longest part of the literal prefix that ends with a separator, e.g. `data/2024/` for `data/2024/*.csv`,
+// which is the deepest directory that every match is inside of. If the Glob has no separators, `/` is used.
+// If the literal prefix has no separators in it, Base returns ""
+func (g *Glob) Base() string {
+	seps := g.opts.Separators
+	if len(seps) == 0 {
+		seps = []rune{'/'}
+	}
+	prefix, _ := g.LiteralPrefix()
+	end := 0
+	for i, r := range prefix {
+		if runesContain(seps, r) {
+			end = i + utf8.RuneLen(r)
+		}
+	}
+	return prefix[:end]
+}
+
+// prefixOf returns the literal prefix of the tree, and whether it is the only string the tree matches
+func prefixOf(tree *ast.Node) (string, bool) {
+	switch tree.Kind {
+	case ast.KindPattern:
+		var buf strings.Builder
+		for _, child := range tree.Children {
+			prefix, complete := prefixOf(child)
+			buf.WriteString(prefix)
+			if !complete {
+				return buf.String(), false
+			}
+		}
+		return buf.String(), true
+
+	case ast.KindText:
+		return tree.Value.(ast.Text).Text, true
+
+	case ast.KindNothing:
+		return "", true
+
+	case ast.KindCapture:
+		if tree.Value.(ast.Capture).Quantifier != "@" {
+			// the other quantifiers can match the empty string, or are negations
+			return "", false
+		}
+		fallthrough
+
+	case ast.KindAnyOf:
+		var prefixes []string
+		complete := true
+		for _, child := range tree.Children {
+			prefix, c := prefixOf(child)
+			prefixes = append(prefixes, prefix)
+			complete = complete && c
+		}
+		return commonPrefix(prefixes, complete)
+
+	case ast.KindSequence:
+		return commonPrefix(tree.Value.(ast.Sequence).Values(), true)
+	}
+	return "", false
+}
+
+// commonPrefix returns the longest prefix shared by all the alternatives, and whether it is the only string they match,
+// which is only the case if every alternative is complete and they are all the same
+func commonPrefix(alternatives []string, complete bool) (string, bool) {
+	if len(alternatives) == 0 {
+		return "", true
+	}
+	prefix := alternatives[0]
+	for _, alt := range alternatives[1:] {
+		if alt != prefix {
+			complete = false
+		}
+		i := 0
+		for i < len(prefix) && i < len(alt) && prefix[i] == alt[i] {
+			i++
+		}
+		prefix = prefix[:i]
+	}
+	// the common prefix must not end in the middle of a multi-byte character
+	for !utf8.ValidString(prefix) {
+		prefix = prefix[:len(prefix)-1]
+	}
+	return prefix, complete
+}
@@ -0,0 +1,53 @@
+package glob
+
+import (
+	"testing"
+)
+
+func TestLiteralPrefix(t *testing.T) {
+	for _, test := range []struct {
+		pattern  string
+		seps     []rune
+		prefix   string
+		complete bool
+		base     string
+	}{
+		{"", []rune{'/'}, "", true, ""},
+		{"a/b/c.txt", []rune{'/'}, "a/b/c.txt", true, "a/b/"},
+		{"a/b/*.go", []rune{'/'}, "a/b/", false, "a/b/"},
+		{"a/b*/c", []rune{'/'}, "a/b", false, "a/"},
+		{"*.go", []rune{'/'}, "", false, ""},
+		{`a\*b/\{c\}/*`, []rune{'/'}, "a*b/{c}/", false, "a*b/{c}/"},
+		{"{abc,abd}*", []rune{'/'}, "ab", false, ""},
+		{"x/{abc,abc}/y", []rune{'/'}, "x/abc/y", true, "x/abc/"},
+		{"x/{a/b,a/c}/y", []rune{'/'}, "x/a/", false, "x/a/"},
+		{"x/{a,ab}", []rune{'/'}, "x/a", false, "x/"},
+		{"data/@(2024)/@(jan|jun)/*", []rune{'/'}, "data/2024/j", false, "data/2024/"},
+		{"data/@<year>(2024)/x", []rune{'/'}, "data/2024/x", true, "data/2024/"},
+		{"data/?(x)/y", []rune{'/'}, "data/", false, "data/"},
+		{"data/!(x)/y", []rune{'/'}, "data/", false, "data/"},
+		{"shard-{100..127}/*", []rune{'/'}, "shard-1", false, ""},
+		{"shard-{007..7}/*", []rune{'/'}, "shard-007/", false, "shard-007/"},
+		{"日本/{語a,語b}", []rune{'/'}, "日本/語", false, "日本/"},
+		{"{é,ê}", nil, "", false, ""},
+		{"a.b.*", []rune{'.'}, "a.b.", false, "a.b."},
+		{"a/b.*", nil, "a/b.", false, "a/"},
+		{"a/b|c/*", []rune{'/', '|'}, "a/b|c/", false, "a/b|c/"},
+	} {
+		t.Run("", func(t *testing.T) {
+			g := MustCompile(test.pattern, test.seps...)
+			prefix, complete := g.LiteralPrefix()
+			if prefix != test.prefix || complete != test.complete {
+				t.Errorf("pattern %q should have literal prefix %q (complete: %v), but got %q (complete: %v)", test.pattern, test.prefix, test.complete, prefix, complete)
+			}
+			if base := g.Base(); base != test.base {
+				t.Errorf("pattern %q should have base %q, but got %q", test.pattern, test.base, base)
+			}
+		})
+	}
+
+	g := MustCompileWithOptions("a/b/*", Options{Separators: []rune{'/'}, IgnoreCase: true})
+	if prefix, complete := g.LiteralPrefix(); prefix != "" || complete || g.Base() != "" {
+		t.Errorf("pattern %q ignoring case should have no literal prefix, but got %q (complete: %v)", g.Pattern(), prefix, complete)
+	}
+}
@@ -0,0 +1,94 @@
+package glob
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pachyderm/ohmyglob/compiler"
+	"github.com/pachyderm/ohmyglob/syntax/ast"
+)
+
+// Render is the inverse of Capture: it builds the string that the pattern matches with the given capture values,
+// e.g. `data/@(*)/@(*).csv` renders []string{"2024", "jan"} as `data/2024/jan.csv`.
+// captures holds one value per capture group, in the same order as Capture()[1:], including groups nested inside others.
+// Everything outside of the captures must be literal (i.e. match exactly one string), and each value must match its group's subpattern.
+// The value of a nested group is not used to build the result, but it is still checked against what the result captures
+func (g *Glob) Render(captures []string) (string, error) {
+	if n := g.NumCaptures(); len(captures) != n {
+		return "", fmt.Errorf("glob: pattern %q has %d captures, but %d values were given", g.pattern, n, len(captures))
+	}
+
+	var buf strings.Builder
+	next := 0
+	if err := g.render(&buf, g.tree, captures, &next); err != nil {
+		return "", err
+	}
+	result := buf.String()
+
+	// the values may still be ambiguous, e.g. `@(*)@(*)` cannot capture "a" and "b" from "ab"
+	recaptured, err := g.CaptureContext(context.Background(), result)
+	if err != nil {
+		return "", err
+	}
+	if len(recaptured) == 0 || !equalStrings(recaptured[1:], captures) {
+		return "", fmt.Errorf("glob: pattern %q would not capture %q from %q", g.pattern, captures, result)
+	}
+	return result, nil
+}
+
+// equalStrings reports whether a and b hold the same strings, treating nil and empty slices as equal
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// render writes the string for tree to buf, taking values from captures starting at *next
+func (g *Glob) render(buf *strings.Builder, tree *ast.Node, captures []string, next *int) error {
+	switch tree.Kind {
+	case ast.KindPattern:
+		for _, child := range tree.Children {
+			if err := g.render(buf, child, captures, next); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case ast.KindCapture:
+		index := *next
+		// the groups nested inside this one are numbered after it, and are covered by its value
+		*next += len(compiler.Captures(tree))
+
+		r, err := compileRegexp(tree, g.opts, false)
+		if err != nil {
+			return err
+		}
+		ok, err := r.MatchString(captures[index])
+		if err != nil {
+			return &matchError{ErrMatchBudget, err}
+		}
+		if !ok {
+			group := g.CaptureGroups()[index]
+			return fmt.Errorf("glob: capture %d value %q does not match %q", group.Index, captures[index], g.pattern[group.Start:group.End])
+		}
+		buf.WriteString(captures[index])
+		return nil
+	}
+
+	// anything else must be literal, which is the same as expanding to exactly one string,
+	// and any captures inside it are checked against the result by Render
+	*next += len(compiler.Captures(tree))
+	values, err := expandNode(tree, 1)
+	if err != nil || len(values) != 1 {
+		return fmt.Errorf("glob: cannot render pattern %q, since it is not literal outside of its captures", g.pattern)
+	}
+	buf.WriteString(values[0])
+	return nil
+}
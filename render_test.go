@@ -0,0 +1,43 @@
+package glob
+
+import (
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	for _, test := range []struct {
+		pattern  string
+		captures []string
+		rendered string
+		err      bool
+	}{
+		{"data/@(*)/@(*).csv", []string{"2024", "jan"}, "data/2024/jan.csv", false},
+		{"data/x.csv", nil, "data/x.csv", false},
+		{"data/x.csv", []string{}, "data/x.csv", false},
+		{"data/x.csv", []string{"x"}, "", true},
+		{"data/@<year>(*)/@<month>(*).csv", []string{"2024", "jan"}, "data/2024/jan.csv", false},
+		{"shard-{000..127}/@(*)", []string{"x"}, "", true},
+		{"shard-{007..7}/@(*)", []string{"x"}, "shard-007/x", false},
+		{`a\*b/{c}/[d]/@(*)`, []string{"e"}, "a*b/c/d/e", false},
+		{"@(a|b)/?(x)", []string{"b", ""}, "b/", false},
+		{"@(a|b)/?(x)", []string{"c", ""}, "", true},
+		{"@(*)", []string{"a/b"}, "", true},
+		{"@(**)", []string{"a/b"}, "a/b", false},
+		{"+(@([0-9])/)", []string{"1/2/", "2"}, "1/2/", false},
+		{"+(@([0-9])/)", []string{"1/2/", "1"}, "", true},
+		{"@(*)@(*)", []string{"a", "b"}, "", true},
+		{"@(*).csv", []string{"a", "b"}, "", true},
+		{"*/@(*).csv", []string{"a"}, "", true},
+		{"{a,b}/@(*).csv", []string{"a"}, "", true},
+		{"!(*.go)", []string{"main.py"}, "main.py", false},
+		{"!(*.go)", []string{"main.go"}, "", true},
+	} {
+		t.Run("", func(t *testing.T) {
+			g := MustCompile(test.pattern, '/')
+			rendered, err := g.Render(test.captures)
+			if rendered != test.rendered || (err != nil) != test.err {
+				t.Errorf("pattern %q should render %q as %q (error: %v), but got %q (error: %v)", test.pattern, test.captures, test.rendered, test.err, rendered, err)
+			}
+		})
+	}
+}
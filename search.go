@@ -0,0 +1,83 @@
+package glob
+
+import (
+	"context"
+
+	"github.com/dlclark/regexp2"
+)
+
+// searchRegexp returns the unanchored version of the compiled pattern, compiling it if this is the first search
+func (g *Glob) searchRegexp() *regexp2.Regexp {
+	r, err := g.search.get(func() (*regexp2.Regexp, error) {
+		return compileRegexp(g.tree, g.opts, true)
+	})
+	if err != nil {
+		// the anchored version of the same pattern compiled, so this should never happen
+		panic(err)
+	}
+	return r
+}
+
+// findAll calls f with each successive non-overlapping match of the pattern in text, stopping after n matches if n >= 0
+func (g *Glob) findAll(text string, n int, f func(m *regexp2.Match)) {
+	r := g.searchRegexp()
+	var m *regexp2.Match
+	if err := run(context.Background(), func() (err error) {
+		m, err = r.FindStringMatch(text)
+		return err
+	}); err != nil {
+		// the match budget has been exhausted, so something is seriously wrong
+		panic(err)
+	}
+	// end is where the last match ended; as in the regexp package, an empty match there is not reported
+	end := -1
+	for i := 0; m != nil && (n < 0 || i < n); {
+		if m.Length > 0 || m.Index != end {
+			f(m)
+			end = m.Index + m.Length
+			i++
+		}
+		if err := run(context.Background(), func() (err error) {
+			m, err = r.FindNextMatch(m)
+			return err
+		}); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// FindIndex returns the start and end byte offsets of the leftmost match of the pattern anywhere within text,
+// rather than requiring the whole of text to match. If there is no match, FindIndex returns nil
+func (g *Glob) FindIndex(text string) []int {
+	var loc []int
+	g.findAll(text, 1, func(m *regexp2.Match) {
+		offsets := byteOffsets(text)
+		loc = []int{offsets[m.Index], offsets[m.Index+m.Length]}
+	})
+	return loc
+}
+
+// FindAll returns up to n successive non-overlapping matches of the pattern within text, or all of them if n < 0.
+// If there are no matches, FindAll returns nil
+func (g *Glob) FindAll(text string, n int) []string {
+	var matches []string
+	g.findAll(text, n, func(m *regexp2.Match) {
+		matches = append(matches, m.String())
+	})
+	return matches
+}
+
+// FindAllCapture is the same as FindAll, except that each match is returned along with its captured subexpressions,
+// in the same form as the result of Capture
+func (g *Glob) FindAllCapture(text string, n int) [][]string {
+	var matches [][]string
+	g.findAll(text, n, func(m *regexp2.Match) {
+		groups := m.Groups()
+		captures := make([]string, 0, len(groups))
+		for _, gp := range groups {
+			captures = append(captures, gp.Capture.String())
+		}
+		matches = append(matches, captures)
+	})
+	return matches
+}
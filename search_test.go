@@ -0,0 +1,75 @@
+package glob
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindIndex(t *testing.T) {
+	for _, test := range []struct {
+		pattern string
+		text    string
+		loc     []int
+	}{
+		{"*.go", "no go files", nil},
+		{"src/*.go", "error in src/main.go:12", []int{9, 20}},
+		{"[0-9][0-9]:[0-9][0-9]", "at 12:30 and 13:45", []int{3, 8}},
+		{"é*é", "café ééé", []int{6, 12}},
+		{"", "abc", []int{0, 0}},
+		// negations end at the next separator, rather than at the end of the text
+		{"src/!(vendor)", "src/vendor src/lib", []int{11, 18}},
+		{"v!(1*)", "v10 v20", []int{4, 7}},
+	} {
+		t.Run("", func(t *testing.T) {
+			g := MustCompile(test.pattern, '/', ' ', ':')
+			if loc := g.FindIndex(test.text); !reflect.DeepEqual(loc, test.loc) {
+				t.Errorf("pattern %q searching %q should have found %v, but got %v", test.pattern, test.text, test.loc, loc)
+			}
+		})
+	}
+}
+
+func TestFindAll(t *testing.T) {
+	for _, test := range []struct {
+		pattern  string
+		text     string
+		n        int
+		matches  []string
+		captures [][]string
+	}{
+		{"*.go", "README.md LICENSE", -1, nil, nil},
+		{
+			"@(*)/@(*).go", "changed: a/x.go b/y.go c/z.go", -1,
+			[]string{"a/x.go", "b/y.go", "c/z.go"},
+			[][]string{{"a/x.go", "a", "x"}, {"b/y.go", "b", "y"}, {"c/z.go", "c", "z"}},
+		},
+		{
+			"@(*)/@(*).go", "changed: a/x.go b/y.go c/z.go", 2,
+			[]string{"a/x.go", "b/y.go"},
+			[][]string{{"a/x.go", "a", "x"}, {"b/y.go", "b", "y"}},
+		},
+		{"?", "ab", -1, []string{"a", "b"}, [][]string{{"a"}, {"b"}}},
+		// empty matches next to another match are skipped
+		{"*", "ab/cd", -1, []string{"ab", "cd"}, [][]string{{"ab"}, {"cd"}}},
+		{"*", "/ab", -1, []string{"", "ab"}, [][]string{{""}, {"ab"}}},
+		{"src/!(vendor)", "src/vendor src/lib", -1, []string{"src/lib"}, [][]string{{"src/lib", "lib"}}},
+		{"*.!(go)", "a.go b.py", -1, []string{"b.py"}, [][]string{{"b.py", "py"}}},
+		// as with `?`, a match can start in the middle of a word, but it must still match the whole glob
+		{"!(*.go)", "a.go b.py", -1, []string{"go", "b.py"}, [][]string{{"go", "go"}, {"b.py", "b.py"}}},
+	} {
+		t.Run("", func(t *testing.T) {
+			g := MustCompile(test.pattern, '/', ' ')
+			if matches := g.FindAll(test.text, test.n); !reflect.DeepEqual(matches, test.matches) {
+				t.Errorf("pattern %q searching %q should have found %q, but got %q", test.pattern, test.text, test.matches, matches)
+			}
+			if captures := g.FindAllCapture(test.text, test.n); !reflect.DeepEqual(captures, test.captures) {
+				t.Errorf("pattern %q searching %q should have captured %q, but got %q", test.pattern, test.text, test.captures, captures)
+			}
+			for _, m := range test.matches {
+				if !g.Match(m) {
+					t.Errorf("pattern %q found %q, which it does not match", test.pattern, m)
+				}
+			}
+		})
+	}
+}
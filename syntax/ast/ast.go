@@ -26,6 +26,17 @@ type Text struct {
 
 type Capture struct {
 	Quantifier string
+	Name       string
+	// Start and End are the byte offsets of the capture in the source pattern, if the lexer reported them
+	Start, End int
+}
+
+// Sequence is a shell style sequence expression, e.g. `{1..10}`, `{01..12}`, `{a..f}` or `{0..100..5}`
+type Sequence struct {
+	// Start and End are the first and last values, as written, so that any zero-padding is kept
+	Start, End string
+	// Step is the distance between successive values, and is always positive
+	Step int
 }
 
 type Kind int
@@ -42,6 +53,7 @@ const (
 	KindSuper
 	KindSingle
 	KindAnyOf
+	KindSequence
 )
 
 type Node struct {
@@ -113,6 +125,8 @@ func (k Kind) String() string {
 		return "Single"
 	case KindAnyOf:
 		return "AnyOf"
+	case KindSequence:
+		return "Sequence"
 	default:
 		return ""
 	}
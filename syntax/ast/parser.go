@@ -29,6 +29,19 @@ type Lexer interface {
 	Next() lexer.Token
 }
 
+// Spanner is implemented by lexers that can report where in the source the token most recently returned by Next came from.
+// If the lexer given to Parse implements it, the source spans of captures are recorded in the tree
+type Spanner interface {
+	Span() (start, end int)
+}
+
+func span(lex Lexer) (int, int) {
+	if s, ok := lex.(Spanner); ok {
+		return s.Span()
+	}
+	return 0, 0
+}
+
 type parseFn func(*Node, Lexer) (parseFn, *Node, error)
 
 func Parse(lexer Lexer) (*Node, error) {
@@ -88,8 +101,26 @@ func parserMain(tree *Node, lex Lexer) (parseFn, *Node, error) {
 
 			return parserMain, p, nil
 
+		case lexer.Sequence:
+			seq, err := ParseSequence(token.Raw)
+			if err != nil {
+				return nil, tree, err
+			}
+			Insert(tree, NewNode(KindSequence, seq))
+			return parserMain, tree, nil
+
 		case lexer.CaptureOpen:
-			a := NewNode(KindCapture, Capture{token.Raw[:1]})
+			c := Capture{Quantifier: token.Raw[:1]}
+			if c.Quantifier == "^" {
+				// `^(...)` is another way of writing the negation `!(...)`
+				c.Quantifier = "!"
+			}
+			c.Start, _ = span(lex)
+			if end := strings.IndexByte(token.Raw, '>'); end > 0 {
+				// named captures look like `@<name>(`
+				c.Name = token.Raw[2:end]
+			}
+			a := NewNode(KindCapture, c)
 			Insert(tree, a)
 
 			p := NewNode(KindPattern, nil)
@@ -107,6 +138,10 @@ func parserMain(tree *Node, lex Lexer) (parseFn, *Node, error) {
 			return parserMain, tree.Parent.Parent, nil
 
 		case lexer.CaptureClose:
+			if c, ok := tree.Parent.Value.(Capture); ok {
+				_, c.End = span(lex)
+				tree.Parent.Value = c
+			}
 			return parserMain, tree.Parent.Parent, nil
 
 		default:
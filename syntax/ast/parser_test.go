@@ -231,6 +231,18 @@ func TestParseString(t *testing.T) {
 				),
 			),
 		},
+		{
+			//pattern: "x{01..10..3}"
+			tokens: []lexer.Token{
+				{lexer.Text, "x"},
+				{lexer.Sequence, "01..10..3"},
+				{lexer.EOF, ""},
+			},
+			tree: NewNode(KindPattern, nil,
+				NewNode(KindText, Text{Text: "x"}),
+				NewNode(KindSequence, Sequence{Start: "01", End: "10", Step: 3}),
+			),
+		},
 	} {
 		lexer := &stubLexer{tokens: test.tokens}
 		result, err := Parse(lexer)
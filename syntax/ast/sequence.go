@@ -0,0 +1,120 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxSequenceLen is the largest number of values that a sequence expression may have
+const MaxSequenceLen = 1 << 16
+
+// ParseSequence parses the inside of a sequence expression, e.g. `1..10` or `a..z..2`.
+// As in bash, a step of 0 is treated as 1, and the sign of the step is ignored, since the direction comes from Start and End
+func ParseSequence(raw string) (Sequence, error) {
+	parts := strings.Split(raw, "..")
+	// strconv accepts a leading `+`, but the lexer does not
+	if (len(parts) != 2 && len(parts) != 3) || strings.ContainsRune(raw, '+') {
+		return Sequence{}, fmt.Errorf("invalid sequence {%s}", raw)
+	}
+	seq := Sequence{Start: parts[0], End: parts[1], Step: 1}
+	if len(parts) == 3 {
+		step, err := strconv.ParseInt(parts[2], 10, 32)
+		if err != nil {
+			return Sequence{}, fmt.Errorf("invalid sequence step in {%s}", raw)
+		}
+		if step < 0 {
+			step = -step
+		}
+		if step > 0 {
+			seq.Step = int(step)
+		}
+	}
+	if _, _, err := seq.bounds(); err != nil {
+		return Sequence{}, fmt.Errorf("invalid sequence {%s}: %v", raw, err)
+	}
+	if n := seq.Len(); n > MaxSequenceLen {
+		return Sequence{}, fmt.Errorf("sequence {%s} has %d values, more than the maximum of %d", raw, n, MaxSequenceLen)
+	}
+	return seq, nil
+}
+
+// bounds returns the first and last values of the sequence as numbers, which are code points for letter sequences
+func (s Sequence) bounds() (int, int, error) {
+	if s.isLetters() {
+		return int(s.Start[0]), int(s.End[0]), nil
+	}
+	start, err := strconv.ParseInt(s.Start, 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := strconv.ParseInt(s.End, 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(start), int(end), nil
+}
+
+func (s Sequence) isLetters() bool {
+	isLetter := func(v string) bool {
+		return len(v) == 1 && (v[0] >= 'a' && v[0] <= 'z' || v[0] >= 'A' && v[0] <= 'Z')
+	}
+	return isLetter(s.Start) && isLetter(s.End)
+}
+
+// width returns the width that numbers should be zero-padded to, which as in bash is
+// the width of the longer of Start and End if either of them has a leading zero, and 0 otherwise
+func (s Sequence) width() int {
+	padded := func(v string) bool {
+		v = strings.TrimPrefix(v, "-")
+		return len(v) > 1 && v[0] == '0'
+	}
+	if s.isLetters() || !(padded(s.Start) || padded(s.End)) {
+		return 0
+	}
+	if len(s.Start) > len(s.End) {
+		return len(s.Start)
+	}
+	return len(s.End)
+}
+
+// Len returns the number of values in the sequence
+func (s Sequence) Len() int {
+	start, end, err := s.bounds()
+	if err != nil {
+		return 0
+	}
+	step := s.Step
+	if step <= 0 {
+		step = 1
+	}
+	if end < start {
+		start, end = end, start
+	}
+	return (end-start)/step + 1
+}
+
+// Values returns each of the values in the sequence, in order, counting down if End is before Start
+func (s Sequence) Values() []string {
+	start, end, err := s.bounds()
+	if err != nil {
+		return nil
+	}
+	step := s.Step
+	if step <= 0 {
+		step = 1
+	}
+	if end < start {
+		step = -step
+	}
+	width := s.width()
+	values := make([]string, 0, s.Len())
+	for v := start; (step > 0 && v <= end) || (step < 0 && v >= end); v += step {
+		if s.isLetters() {
+			values = append(values, string(rune(v)))
+		} else {
+			values = append(values, fmt.Sprintf("%0*d", width, v))
+		}
+	}
+	return values
+}
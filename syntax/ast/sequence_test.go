@@ -0,0 +1,44 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSequence(t *testing.T) {
+	for id, test := range []struct {
+		raw    string
+		seq    Sequence
+		values []string
+		err    bool
+	}{
+		{raw: "1..5", seq: Sequence{Start: "1", End: "5", Step: 1}, values: []string{"1", "2", "3", "4", "5"}},
+		{raw: "5..1..2", seq: Sequence{Start: "5", End: "1", Step: 2}, values: []string{"5", "3", "1"}},
+		{raw: "0..10..-5", seq: Sequence{Start: "0", End: "10", Step: 5}, values: []string{"0", "5", "10"}},
+		{raw: "1..3..0", seq: Sequence{Start: "1", End: "3", Step: 1}, values: []string{"1", "2", "3"}},
+		{raw: "08..11", seq: Sequence{Start: "08", End: "11", Step: 1}, values: []string{"08", "09", "10", "11"}},
+		{raw: "1..010..3", seq: Sequence{Start: "1", End: "010", Step: 3}, values: []string{"001", "004", "007", "010"}},
+		{raw: "-02..1", seq: Sequence{Start: "-02", End: "1", Step: 1}, values: []string{"-02", "-01", "000", "001"}},
+		{raw: "x..z", seq: Sequence{Start: "x", End: "z", Step: 1}, values: []string{"x", "y", "z"}},
+		{raw: "Z..a..3", seq: Sequence{Start: "Z", End: "a", Step: 3}, values: []string{"Z", "]", "`"}},
+		{raw: "1..a", err: true},
+		{raw: "1..2..3..4", err: true},
+		{raw: "+1..2", err: true},
+		{raw: "0..99999999", err: true},
+	} {
+		seq, err := ParseSequence(test.raw)
+		if (err != nil) != test.err {
+			t.Errorf("[%d] %q: unexpected error: %v", id, test.raw, err)
+			continue
+		}
+		if test.err {
+			continue
+		}
+		if seq != test.seq {
+			t.Errorf("[%d] %q: ParseSequence():\nact:\t%+v\nexp:\t%+v", id, test.raw, seq, test.seq)
+		}
+		if values := seq.Values(); !reflect.DeepEqual(values, test.values) || seq.Len() != len(values) {
+			t.Errorf("[%d] %q: Values():\nact:\t%q (Len %d)\nexp:\t%q", id, test.raw, values, seq.Len(), test.values)
+		}
+	}
+}
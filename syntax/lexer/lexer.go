@@ -3,6 +3,8 @@ package lexer
 import (
 	"bytes"
 	"fmt"
+	"strings"
+	"unicode"
 	"unicode/utf8"
 
 	"github.com/gobwas/glob/util/runes"
@@ -25,6 +27,9 @@ const (
 	char_capture_pipe  = '|'
 	char_capture_close = ')'
 	char_range_between = '-'
+	char_name_open     = '<'
+	char_name_close    = '>'
+	char_sequence      = ".."
 )
 
 var (
@@ -79,6 +84,8 @@ type lexer struct {
 	err  error
 
 	tokens       tokens
+	spans        [][2]int
+	span         [2]int
 	termsLevel   int
 	captureLevel int
 
@@ -100,13 +107,26 @@ func (l *lexer) Next() Token {
 		return Token{Error, l.err.Error()}
 	}
 	if !l.tokens.empty() {
+		l.span = l.spans[0]
+		l.spans = l.spans[1:]
 		return l.tokens.shift()
 	}
 
+	start := l.pos
 	l.fetchItem()
+	// every token from a single fetch gets the same span, which is only imprecise for character classes
+	for len(l.spans) < len(l.tokens) {
+		l.spans = append(l.spans, [2]int{start, l.pos})
+	}
 	return l.Next()
 }
 
+// Span returns the start and end byte offsets in the source of the token most recently returned by Next.
+// The tokens that make up a character class (e.g. `[a-z]`) all span the whole class
+func (l *lexer) Span() (int, int) {
+	return l.span[0], l.span[1]
+}
+
 func (l *lexer) peek() (r rune, w int) {
 	if l.pos == len(l.data) {
 		return eof, 0
@@ -185,6 +205,9 @@ func (l *lexer) fetchItem() {
 	case r == eof:
 		l.tokens.push(Token{EOF, ""})
 
+	case r == char_terms_open && l.fetchSequence():
+		// sequences such as `{1..10}` are handled by fetchSequence
+
 	case r == char_terms_open:
 		l.termsEnter()
 		l.tokens.push(Token{TermsOpen, string(r)})
@@ -207,6 +230,9 @@ func (l *lexer) fetchItem() {
 		l.tokens.push(Token{CaptureOpen, string(char_capture_at) + string(r)})
 		l.captureEnter()
 
+	case isQuantifier(r) && l.fetchNamedCapture(r):
+		// named captures such as `@<name>(` are handled by fetchNamedCapture
+
 	case r == char_capture_at:
 		switch s, _ := l.peek(); s {
 		case char_capture_open:
@@ -289,6 +315,85 @@ func (l *lexer) fetchItem() {
 	}
 }
 
+func isQuantifier(r rune) bool {
+	switch r {
+	case char_capture_at, char_any, char_capture_plus, char_single, char_not_exclaim, char_not_caret:
+		return true
+	}
+	return false
+}
+
+func isNameRune(r rune, first bool) bool {
+	return r == '_' || unicode.IsLetter(r) || (!first && unicode.IsDigit(r))
+}
+
+// fetchNamedCapture checks if the quantifier q is followed by `<name>(`,
+// and if so consumes the name and pushes a CaptureOpen token for it
+func (l *lexer) fetchNamedCapture(q rune) bool {
+	rest := l.data[l.pos:]
+	if len(rest) == 0 || rest[0] != char_name_open {
+		return false
+	}
+	i := 1
+	for i < len(rest) {
+		r, w := utf8.DecodeRuneInString(rest[i:])
+		if !isNameRune(r, i == 1) {
+			break
+		}
+		i += w
+	}
+	if i == 1 || i+1 >= len(rest) || rest[i] != char_name_close || rest[i+1] != char_capture_open {
+		return false
+	}
+	l.seek(i + 2)
+	l.tokens.push(Token{CaptureOpen, string(q) + rest[:i+2]})
+	l.captureEnter()
+	return true
+}
+
+// fetchSequence checks if the `{` just read starts a sequence expression such as `{1..10}`, `{01..12}`, `{a..f}` or `{0..100..5}`,
+// and if so consumes it and pushes a Sequence token for the part between the braces
+func (l *lexer) fetchSequence() bool {
+	rest := l.data[l.pos:]
+	end := strings.IndexByte(rest, char_terms_close)
+	if end < 0 || !isSequence(rest[:end]) {
+		return false
+	}
+	l.seek(end + 1)
+	l.tokens.push(Token{Sequence, rest[:end]})
+	return true
+}
+
+// isSequence reports whether s is `start..end` or `start..end..step`, where start and end are
+// either both integers or both single ASCII letters, and step is an integer
+func isSequence(s string) bool {
+	parts := strings.Split(s, char_sequence)
+	if len(parts) != 2 && len(parts) != 3 {
+		return false
+	}
+	if len(parts) == 3 && !isInteger(parts[2]) {
+		return false
+	}
+	return (isInteger(parts[0]) && isInteger(parts[1])) || (isLetter(parts[0]) && isLetter(parts[1]))
+}
+
+func isInteger(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isLetter(s string) bool {
+	return len(s) == 1 && (s[0] >= 'a' && s[0] <= 'z' || s[0] >= 'A' && s[0] <= 'Z')
+}
+
 func (l *lexer) fetchRange() {
 	var seenNot bool
 	var inPOSIX bool
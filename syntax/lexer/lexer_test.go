@@ -331,6 +331,73 @@ func TestLexGood(t *testing.T) {
 				{EOF, ""},
 			},
 		},
+		{
+			pattern: "data/@<year>(*)/*<rest_1>(a|b)<x>(",
+			items: []Token{
+				{Text, "data/"},
+				{CaptureOpen, "@<year>("},
+				{Any, "*"},
+				{CaptureClose, ")"},
+				{Text, "/"},
+				{CaptureOpen, "*<rest_1>("},
+				{Text, "a"},
+				{Separator, "|"},
+				{Text, "b"},
+				{CaptureClose, ")"},
+				{Text, "<x>"},
+				{CaptureOpen, "@("},
+				{EOF, ""},
+			},
+		},
+		{
+			pattern: "^<not>(a)",
+			items: []Token{
+				{CaptureOpen, "^<not>("},
+				{Text, "a"},
+				{CaptureClose, ")"},
+				{EOF, ""},
+			},
+		},
+		{
+			pattern: "*<1>(a)@<>(b)",
+			items: []Token{
+				{Any, "*"},
+				{Text, "<1>"},
+				{CaptureOpen, "@("},
+				{Text, "a"},
+				{CaptureClose, ")"},
+				{Text, "@<>"},
+				{CaptureOpen, "@("},
+				{Text, "b"},
+				{CaptureClose, ")"},
+				{EOF, ""},
+			},
+		},
+		{
+			pattern: "shard-{000..127}/{a..f..2}{1..x}",
+			items: []Token{
+				{Text, "shard-"},
+				{Sequence, "000..127"},
+				{Text, "/"},
+				{Sequence, "a..f..2"},
+				{TermsOpen, "{"},
+				{Text, "1..x"},
+				{TermsClose, "}"},
+				{EOF, ""},
+			},
+		},
+		{
+			pattern: "{-10..10..-5}{1..2,3}",
+			items: []Token{
+				{Sequence, "-10..10..-5"},
+				{TermsOpen, "{"},
+				{Text, "1..2"},
+				{Separator, ","},
+				{Text, "3"},
+				{TermsClose, "}"},
+				{EOF, ""},
+			},
+		},
 	} {
 		lexer := NewLexer(test.pattern)
 		for i, exp := range test.items {
@@ -344,3 +411,15 @@ func TestLexGood(t *testing.T) {
 		}
 	}
 }
+
+func TestLexSpan(t *testing.T) {
+	pattern := "ab@<x>(c|*)[!d-e]"
+	lexer := NewLexer(pattern)
+	for i, exp := range []string{"ab", "@<x>(", "c", "|", "*", ")", "[!d-e]", "[!d-e]", "[!d-e]", "[!d-e]", ""} {
+		act := lexer.Next()
+		start, end := lexer.Span()
+		if pattern[start:end] != exp {
+			t.Errorf("%q: wrong %d-th item span: exp: %q; act: %q (%s)", pattern, i, exp, pattern[start:end], act)
+		}
+	}
+}
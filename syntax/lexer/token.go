@@ -23,6 +23,7 @@ const (
 	TermsClose
 	CaptureOpen
 	CaptureClose
+	Sequence
 )
 
 func (tt TokenType) String() string {
@@ -81,6 +82,9 @@ func (tt TokenType) String() string {
 	case CaptureClose:
 		return "capture_close"
 
+	case Sequence:
+		return "sequence"
+
 	default:
 		return "undef"
 	}
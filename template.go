@@ -0,0 +1,84 @@
+package glob
+
+import "sync/atomic"
+
+// Template is a replacement template that has been parsed and checked against the capture groups of a Glob,
+// so that it can be used repeatedly without being parsed again.
+// The template syntax is the same as for Glob.Replace
+type Template struct {
+	g      *Glob
+	source string
+	pieces []piece
+}
+
+// templateCache holds the most recently used template of a Glob, so that using the same template repeatedly only parses it once
+type templateCache struct {
+	last atomic.Value // *Template
+}
+
+// parse returns the pieces of the template, parsed as Replace does, which does not check the references to capture groups
+func (c *templateCache) parse(template string, names []string) []piece {
+	if t, ok := c.last.Load().(*Template); ok && t.source == template {
+		return t.pieces
+	}
+	pieces, _ := parseTemplate(template, names, false)
+	c.last.Store(&Template{source: template, pieces: pieces})
+	return pieces
+}
+
+// CompileTemplate parses the replacement template, and checks that every $n, ${n} or ${name} in it refers to a capture group in the pattern
+func (g *Glob) CompileTemplate(template string) (*Template, error) {
+	pieces, err := parseTemplate(template, g.names, true)
+	if err != nil {
+		return nil, err
+	}
+	return &Template{g: g, source: template, pieces: pieces}, nil
+}
+
+// MustCompileTemplate is the same as CompileTemplate, except that if CompileTemplate returns error, this will panic
+func (g *Glob) MustCompileTemplate(template string) *Template {
+	t, err := g.CompileTemplate(template)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// String returns the source text of the template
+func (t *Template) String() string {
+	return t.source
+}
+
+// Replace runs the pattern the template was compiled with on the given fixture, and fills in the template with the captured subexpressions.
+// Unlike Glob.Replace, if the fixture does not match, Replace returns false instead of a partially filled template
+func (t *Template) Replace(fixture string) (string, bool) {
+	captures := t.g.Capture(fixture)
+	if captures == nil {
+		return "", false
+	}
+	return string(appendPieces(nil, t.pieces, captures)), true
+}
+
+// AppendReplace is the same as Replace, except that the fixture is a byte slice, and the result is appended to dst.
+// If the fixture does not match, dst is returned unchanged along with false
+func (t *Template) AppendReplace(dst, fixture []byte) ([]byte, bool) {
+	captures := t.g.CaptureBytes(fixture)
+	if captures == nil {
+		return dst, false
+	}
+	return appendPiecesBytes(dst, t.pieces, captures), true
+}
+
+// ReplaceStrict is the same as Replace, except that it returns an error if the template refers to a capture group that does not exist,
+// and ErrNoMatch if the fixture does not match
+func (g *Glob) ReplaceStrict(fixture, template string) (string, error) {
+	t, err := g.CompileTemplate(template)
+	if err != nil {
+		return "", err
+	}
+	result, ok := t.Replace(fixture)
+	if !ok {
+		return "", ErrNoMatch
+	}
+	return result, nil
+}
@@ -0,0 +1,59 @@
+package glob
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompileTemplate(t *testing.T) {
+	g := MustCompile("name=@<name>(*)/year=(*)/(*).dat", '/')
+	for _, test := range []struct {
+		template string
+		valid    bool
+	}{
+		{"", true},
+		{"$0", true},
+		{"$3 of ${name} from ${2}", true},
+		{"$$4 costs $5", false},
+		{"$$4 costs", true},
+		{"${year}", false},
+		{"$01", false},
+		{"$x ${ $", true},
+	} {
+		_, err := g.CompileTemplate(test.template)
+		if (err == nil) != test.valid {
+			t.Errorf("template %q should be valid: %v, but got error %v", test.template, test.valid, err)
+		}
+		_, err = g.ReplaceStrict("name=adele/year=2016/photos.dat", test.template)
+		if (err == nil) != test.valid {
+			t.Errorf("strict replacement with template %q should be valid: %v, but got error %v", test.template, test.valid, err)
+		}
+	}
+}
+
+func TestTemplateReplace(t *testing.T) {
+	g := MustCompile("name=@<name>(*)/year=(*)/(*).dat", '/')
+	tmpl := g.MustCompileTemplate("$3 of ${name} from $2, $$$1")
+	if tmpl.String() != "$3 of ${name} from $2, $$$1" {
+		t.Errorf("template source should be preserved, but got %q", tmpl)
+	}
+
+	for _, test := range []struct {
+		fixture     string
+		replacement string
+		ok          bool
+	}{
+		{"name=adele/year=2016/photos.dat", "photos of adele from 2016, $adele", true},
+		{"name=/year=/.dat", " of  from , $", true},
+		{"name=adele/year=2016/photos.txt", "", false},
+	} {
+		replacement, ok := tmpl.Replace(test.fixture)
+		if replacement != test.replacement || ok != test.ok {
+			t.Errorf("template %q on %q should have returned %q, %v but got %q, %v", tmpl, test.fixture, test.replacement, test.ok, replacement, ok)
+		}
+		replacement, err := g.ReplaceStrict(test.fixture, tmpl.String())
+		if replacement != test.replacement || (err == nil) != test.ok || (err != nil && !errors.Is(err, ErrNoMatch)) {
+			t.Errorf("strict replacement %q on %q should have returned %q, %v but got %q, %v", tmpl, test.fixture, test.replacement, test.ok, replacement, err)
+		}
+	}
+}
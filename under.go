@@ -0,0 +1,192 @@
+package glob
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/dlclark/regexp2"
+
+	"github.com/pachyderm/ohmyglob/syntax/ast"
+)
+
+// segmentMatcher matches the separator-delimited segments at the start of a pattern, up to the first part of it that could match a separator
+type segmentMatcher struct {
+	once sync.Once
+	err  error
+
+	// segments match each whole segment, in order
+	segments []*regexp2.Regexp
+	// complete is true if the pattern is exactly its segments joined by separators
+	complete bool
+	// partial is the literal text at the start of the segment after the last whole one, if the pattern is not complete
+	partial string
+}
+
+// CouldMatchUnder returns false if nothing under the prefix (e.g. a directory) can match the pattern,
+// i.e. there is no string starting with the prefix followed by a separator that the pattern matches.
+// It is computed from the separator-delimited segments of the pattern, so is much cheaper than trial matching,
+// but it is conservative: it returns true whenever it cannot be sure, e.g. after a `**` in the pattern,
+// or if the Glob was compiled without separators
+func (g *Glob) CouldMatchUnder(prefix string) bool {
+	seps := g.opts.Separators
+	if len(seps) == 0 {
+		return true
+	}
+	prefix = strings.TrimRight(prefix, string(seps))
+	if prefix == "" {
+		return true
+	}
+
+	m := g.segmentMatcher()
+	if m.err != nil {
+		return true
+	}
+	components := splitSeparators(prefix, seps)
+	for i, component := range components {
+		if i == len(m.segments) {
+			if m.complete {
+				// the prefix is at least as deep as the pattern, so nothing under it can match
+				return false
+			}
+			// the rest of the pattern could match any number of segments
+			return strings.HasPrefix(component, m.partial)
+		}
+		if ok, err := m.segments[i].MatchString(component); err == nil && !ok {
+			return false
+		}
+	}
+	return len(components) < len(m.segments) || !m.complete
+}
+
+// segmentMatcher splits the pattern into segments the first time it is needed
+func (g *Glob) segmentMatcher() *segmentMatcher {
+	m := g.segments
+	m.once.Do(func() {
+		seps := g.opts.Separators
+		var current []*ast.Node
+		split := func() {
+			if m.err != nil {
+				return
+			}
+			r, err := compileRegexp(&ast.Node{Kind: ast.KindPattern, Children: current}, g.opts, false)
+			m.segments = append(m.segments, r)
+			m.err = err
+			current = nil
+		}
+
+		for _, child := range g.tree.Children {
+			if child.Kind == ast.KindText {
+				for i, part := range splitSeparators(child.Value.(ast.Text).Text, seps) {
+					if i > 0 {
+						split()
+					}
+					if part != "" {
+						current = append(current, ast.NewNode(ast.KindText, ast.Text{Text: part}))
+					}
+				}
+				continue
+			}
+			if couldMatchSeparator(child, seps) {
+				if !g.opts.IgnoreCase {
+					m.partial, _ = prefixOf(&ast.Node{Kind: ast.KindPattern, Children: current})
+				}
+				return
+			}
+			current = append(current, child)
+		}
+		split()
+		m.complete = true
+	})
+	return m
+}
+
+// splitSeparators splits s at every separator
+func splitSeparators(s string, seps []rune) []string {
+	var parts []string
+	last := 0
+	for i, r := range s {
+		if runesContain(seps, r) {
+			parts = append(parts, s[last:i])
+			last = i + len(string(r))
+		}
+	}
+	return append(parts, s[last:])
+}
+
+func runesContain(runes []rune, r rune) bool {
+	for _, c := range runes {
+		if c == r {
+			return true
+		}
+	}
+	return false
+}
+
+// couldMatchSeparator returns true if the tree could match a string containing a separator, or if it is not sure
+func couldMatchSeparator(tree *ast.Node, seps []rune) bool {
+	switch tree.Kind {
+	// these are compiled to exclude the separators
+	case ast.KindNothing, ast.KindAny, ast.KindSingle:
+		return false
+
+	case ast.KindText:
+		return strings.ContainsAny(tree.Value.(ast.Text).Text, string(seps))
+
+	case ast.KindList:
+		l := tree.Value.(ast.List)
+		if l.Not {
+			return true
+		}
+		chars := []rune(l.Chars)
+		for i := 0; i < len(chars); i++ {
+			lo, hi := chars[i], chars[i]
+			if i+2 < len(chars) && chars[i+1] == '-' {
+				hi = chars[i+2]
+				i += 2
+			}
+			for _, sep := range seps {
+				if lo <= sep && sep <= hi {
+					return true
+				}
+			}
+		}
+		return false
+
+	case ast.KindRange:
+		r := tree.Value.(ast.Range)
+		if r.Not {
+			return true
+		}
+		for _, sep := range seps {
+			if r.Lo <= sep && sep <= r.Hi {
+				return true
+			}
+		}
+		return false
+
+	case ast.KindSequence:
+		for _, v := range tree.Value.(ast.Sequence).Values() {
+			if strings.ContainsAny(v, string(seps)) {
+				return true
+			}
+		}
+		return false
+
+	case ast.KindCapture:
+		if tree.Value.(ast.Capture).Quantifier == "!" {
+			// negations only ever match non-separator characters
+			return false
+		}
+		fallthrough
+
+	case ast.KindPattern, ast.KindAnyOf:
+		for _, child := range tree.Children {
+			if couldMatchSeparator(child, seps) {
+				return true
+			}
+		}
+		return false
+	}
+	// `**` and POSIX classes
+	return true
+}
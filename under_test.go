@@ -0,0 +1,59 @@
+package glob
+
+import (
+	"testing"
+)
+
+func TestCouldMatchUnder(t *testing.T) {
+	for _, test := range []struct {
+		pattern string
+		seps    []rune
+		prefix  string
+		could   bool
+	}{
+		{"a/*/c/**/*.go", []rune{'/'}, "", true},
+		{"a/*/c/**/*.go", []rune{'/'}, "a", true},
+		{"a/*/c/**/*.go", []rune{'/'}, "a/", true},
+		{"a/*/c/**/*.go", []rune{'/'}, "a/b", true},
+		{"a/*/c/**/*.go", []rune{'/'}, "a/b/c", true},
+		{"a/*/c/**/*.go", []rune{'/'}, "a/b/c/d/e", true},
+		{"a/*/c/**/*.go", []rune{'/'}, "b", false},
+		{"a/*/c/**/*.go", []rune{'/'}, "a/b/d", false},
+		{"a/b/*.go", []rune{'/'}, "a/b", true},
+		{"a/b/*.go", []rune{'/'}, "a/b/c", false},
+		{"a/b", []rune{'/'}, "a/b", false},
+		{"/abs/*", []rune{'/'}, "/abs", true},
+		{"/abs/*", []rune{'/'}, "abs", false},
+		{"src/foo**", []rune{'/'}, "src/foobar/x", true},
+		{"src/foo**", []rune{'/'}, "src/bar", false},
+		{"src/@(a|b)/*", []rune{'/'}, "src/b", true},
+		{"src/@(a|b)/*", []rune{'/'}, "src/c", false},
+		{"src/@(a|b/c)/*", []rune{'/'}, "src/b", true},
+		{"src/!(vendor)/*", []rune{'/'}, "src/lib", true},
+		{"src/!(vendor)/*", []rune{'/'}, "src/vendor", false},
+		{"src/[!a]/*", []rune{'/'}, "src/a", true},
+		{"src/[ab]/*", []rune{'/'}, "src/c", false},
+		{"shard-{000..127}/*", []rune{'/'}, "shard-042", true},
+		{"shard-{000..127}/*", []rune{'/'}, "shard-128", false},
+		{"{a,b}/*", []rune{'/'}, "b", true},
+		{"{a,b}/*", []rune{'/'}, "c", false},
+		{"a.*.c", []rune{'.'}, "a.b", true},
+		{"a.*.c", []rune{'.'}, "b.b", false},
+		{"a/b/c", nil, "x", true},
+		{"A/*", []rune{'/'}, "a", false},
+	} {
+		t.Run("", func(t *testing.T) {
+			g := MustCompile(test.pattern, test.seps...)
+			if could := g.CouldMatchUnder(test.prefix); could != test.could {
+				t.Errorf("pattern %q could match under %q should be %v, but got %v", test.pattern, test.prefix, test.could, could)
+			}
+		})
+	}
+
+	g := MustCompileWithOptions("A/B*/**", Options{Separators: []rune{'/'}, IgnoreCase: true})
+	for prefix, could := range map[string]bool{"a": true, "a/bc": true, "a/bc/d": true, "a/c": false, "b": false} {
+		if g.CouldMatchUnder(prefix) != could {
+			t.Errorf("pattern %q ignoring case could match under %q should be %v", g.Pattern(), prefix, could)
+		}
+	}
+}